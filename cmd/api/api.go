@@ -1,24 +1,33 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
 	_ "github.com/jhonjoao/remote-containers/docs"
 	communication "github.com/jhonjoao/remote-containers/internal/communication"
-	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/jhonjoao/remote-containers/internal/pairing"
+	"github.com/jhonjoao/remote-containers/internal/peers"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-var stream network.Stream
-var apiChan <-chan communication.ResponseData
+var logger = logging.Logger("remote-containers/api")
+
+var peerManager *peers.Manager
+var pairings *pairing.Pairing
+var apiListener net.Listener
 
 // @title Gin Swagger Remote Containers API
 // @version 1.0
@@ -28,55 +37,154 @@ var apiChan <-chan communication.ResponseData
 // @host localhost:8080
 // @BasePath /
 // @schemes http
-func StartApi(s network.Stream, channel <-chan communication.ResponseData) {
+func StartApi(manager *peers.Manager, pairing *pairing.Pairing, listener net.Listener) error {
 
-	stream = s
-	apiChan = channel
+	peerManager = manager
+	pairings = pairing
+	apiListener = listener
 
 	r := gin.Default()
 
-	port := 8080
+	// Image references contain "/", which has to be sent URL encoded to
+	// stay inside a single path parameter.
+	r.UseRawPath = true
+	r.UnescapePathValues = true
+
+	port := listener.Addr().(*net.TCPAddr).Port
 
 	url := ginSwagger.URL(fmt.Sprintf("http://localhost:%v/swagger/doc.json", port))
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 
 	r.GET("/", HealthCheck)
+	r.GET("/status", nodeStatus)
+	r.GET("/peers", listPeers)
+	r.GET("/peers/:peer", inspectPeer)
+	r.GET("/discovery", discoveredPeers)
+
+	// The API has no authentication, pairing is only offered to the
+	// machine the node runs on.
+	pairingRoutes := r.Group("/pairing", localOnly)
+	pairingRoutes.POST("/tokens", issuePairingToken)
+	pairingRoutes.GET("/tokens", listPairingTokens)
+	pairingRoutes.DELETE("/tokens/:id", revokePairingToken)
+	pairingRoutes.POST("/join", joinPeer)
+
+	// Every route of the other node is served both unscoped, for when a
+	// single peer is connected, and under the peer it is meant for.
+	registerRoutes(r)
+	registerRoutes(r.Group("/peers/:peer"))
+
+	logger.Infof("HTTP API listening on http://%s", listener.Addr())
+
+	return r.RunListener(listener)
+}
+
+// Listen binds the address the HTTP API is served on. It fails instead of
+// falling back to another port, so the API is always where it was
+// configured to be.
+func Listen(addr string) (net.Listener, error) {
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind the HTTP API to %s: %w", addr, err)
+	}
+
+	return listener, nil
+}
+
+func registerRoutes(r gin.IRoutes) {
+
 	r.GET("/containers/list", listContainers)
 	r.POST("/containers/create", createContainer)
 
 	r.GET("/containers/:id", inspectContainer)
 	r.DELETE("/containers/:id", deleteContainer)
-
-	status, err := Check(port)
-
-	if !status || err != nil {
-		port, _ = GetFreePort()
-	}
-
-	r.Run(fmt.Sprintf(":%v", port))
+	r.GET("/containers/:id/logs", containerLogs)
+	r.GET("/containers/:id/exec", execContainer)
+	r.GET("/containers/:id/stats", containerStats)
+	r.GET("/containers/:id/archive", copyFromContainer)
+	r.PUT("/containers/:id/archive", copyToContainer)
+	r.GET("/stats", allStats)
+	r.GET("/events", dockerEvents)
+
+	r.GET("/images/list", listImages)
+	r.POST("/images/pull", pullImage)
+	r.POST("/images/prune", pruneImages)
+	r.GET("/images/:id", inspectImage)
+	r.DELETE("/images/:id", removeImage)
+	r.POST("/images/:id/tag", tagImage)
+
+	r.GET("/volumes/list", listVolumes)
+	r.POST("/volumes/create", createVolume)
+	r.POST("/volumes/prune", pruneVolumes)
+	r.GET("/volumes/:name", inspectVolume)
+	r.DELETE("/volumes/:name", removeVolume)
+
+	r.GET("/networks/list", listNetworks)
+	r.POST("/networks/create", createNetwork)
+	r.POST("/networks/prune", pruneNetworks)
+	r.GET("/networks/:id", inspectNetwork)
+	r.DELETE("/networks/:id", removeNetwork)
+	r.POST("/networks/:id/connect", connectNetwork)
+	r.POST("/networks/:id/disconnect", disconnectNetwork)
+
+	r.POST("/containers/:id/start", startContainer)
+	r.POST("/containers/:id/stop", stopContainer)
+	r.POST("/containers/:id/restart", restartContainer)
+	r.POST("/containers/:id/kill", killContainer)
+	r.POST("/containers/:id/pause", pauseContainer)
+	r.POST("/containers/:id/unpause", unpauseContainer)
 }
 
 type TransactionRequest struct {
+	Id     string              `json:"Id"`
 	Method string              `json:"Method"`
 	Uri    string              `json:"Uri"`
 	Header map[string][]string `json:"Header"`
 	Body   []byte              `json:"Body"`
 	Params *gin.Params         `json:"Params"`
+	// Ctx is set by the node handling the request and is cancelled when the
+	// requesting node gives up on it.
+	Ctx context.Context `json:"-"`
+	// Peer is the node the request came from and is answered to.
+	Peer *peers.Peer `json:"-"`
 }
 
-func ginContextToBytes(c *gin.Context) ([]byte, error) {
+func ginContextToBytes(c *gin.Context, id string) ([]byte, error) {
 
 	bodyBytes, err := c.GetRawData()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read request body: %v", err)
 	}
 
+	return transactionBytes(c, id, bodyBytes)
+}
+
+func transactionBytes(c *gin.Context, id string, bodyBytes []byte) ([]byte, error) {
+
+	uri := c.Request.RequestURI
+	params := c.Params
+
+	// The other node serves the routes without the /peers/:peer prefix.
+	if c.Param("peer") != "" {
+		_, rest, _ := strings.Cut(strings.TrimPrefix(uri, "/peers/"), "/")
+		uri = "/" + rest
+
+		params = make(gin.Params, 0, len(c.Params))
+		for _, param := range c.Params {
+			if param.Key != "peer" {
+				params = append(params, param)
+			}
+		}
+	}
+
 	requestData := TransactionRequest{
+		Id:     id,
 		Method: c.Request.Method,
-		Uri:    c.Request.RequestURI,
+		Uri:    uri,
 		Header: c.Request.Header,
 		Body:   bodyBytes,
-		Params: &c.Params,
+		Params: &params,
 	}
 
 	jsonData, err := json.Marshal(requestData)
@@ -87,6 +195,104 @@ func ginContextToBytes(c *gin.Context) ([]byte, error) {
 	return jsonData, nil
 }
 
+// PeerHeader selects the peer a request is sent to by its alias or peer ID,
+// like the /peers/:peer prefix. Both can be left out while a single peer is
+// connected.
+const PeerHeader = "X-Peer"
+
+// targetPeer returns the peer the gin request is meant for, along with the
+// status code to answer with when there is none.
+func targetPeer(c *gin.Context) (*peers.Peer, int, error) {
+
+	selected := c.Param("peer")
+	if selected == "" {
+		selected = c.GetHeader(PeerHeader)
+	}
+
+	remote, err := peerManager.Select(selected)
+	switch {
+	case errors.Is(err, peers.ErrNoPeers):
+		return nil, http.StatusServiceUnavailable, err
+	case errors.Is(err, peers.ErrPeerAmbiguous):
+		return nil, http.StatusConflict, fmt.Errorf("%w under /peers/:peer or with the %s header", err, PeerHeader)
+	case err != nil:
+		return nil, http.StatusNotFound, err
+	}
+
+	return remote, http.StatusOK, nil
+}
+
+// forwardRequest sends the gin request to the other node and waits for the
+// response that carries the same request id. Error envelopes are turned into
+// an error together with the status code chosen by the remote node.
+func forwardRequest(c *gin.Context) (communication.Envelope, int, error) {
+	return forwardRequestWithTimeout(c, communication.RequestTimeout)
+}
+
+func forwardRequestWithTimeout(c *gin.Context, timeout time.Duration) (communication.Envelope, int, error) {
+
+	remote, status, err := targetPeer(c)
+	if err != nil {
+		return communication.Envelope{}, status, err
+	}
+
+	id := uuid.New().String()
+
+	requestData, err := ginContextToBytes(c, id)
+	if err != nil {
+		return communication.Envelope{}, http.StatusInternalServerError, err
+	}
+
+	remote.Pending.Register(id)
+
+	err = remote.Send(communication.Envelope{
+		Id:      id,
+		Kind:    communication.KindRequest,
+		Payload: requestData,
+	})
+	if err != nil {
+		remote.Pending.Remove(id)
+		return communication.Envelope{}, http.StatusServiceUnavailable, fmt.Errorf("error sending request to another node: %w", err)
+	}
+
+	response, err := remote.Pending.Wait(c.Request.Context(), id, timeout)
+	if err != nil {
+		return communication.Envelope{}, http.StatusGatewayTimeout, err
+	}
+
+	status = response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if response.Kind == communication.KindError {
+		if status < http.StatusBadRequest {
+			status = http.StatusInternalServerError
+		}
+		return response, status, errors.New(response.Error)
+	}
+
+	return response, status, nil
+}
+
+// relayResponse forwards the request and answers with the payload sent back
+// by the other node under key.
+func relayResponse(c *gin.Context, key string) {
+
+	response, status, err := forwardRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	payload := json.RawMessage(response.Payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+
+	c.JSON(status, gin.H{key: payload})
+}
+
 // @Summary Show the status of server.
 // @Accept */*
 // @Produce json
@@ -107,64 +313,42 @@ func HealthCheck(c *gin.Context) {
 // @Router /containers/list [get]
 func listContainers(c *gin.Context) {
 
-	requestData, err := ginContextToBytes(c)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	err = communication.WriteData(stream, requestData)
+	response, status, err := forwardRequest(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprint("Error sending request to another node:", err.Error())})
-		return
-	}
-
-	response := <-apiChan
-
-	if response.Err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
 	var result []types.Container
 
-	json.Unmarshal(response.Data, &result)
+	json.Unmarshal(response.Payload, &result)
 
-	c.JSON(http.StatusOK, gin.H{"containers": result})
+	c.JSON(status, gin.H{"containers": result})
 }
 
 // @Summary creates a new Docker container
 // @Accept json
 // @Produce json
 // @Param data body docker.CreateRequest true "body data"
-// @Success 200	{object} map[string]interface{}  "ok"
+// @Success 201	{object} map[string]interface{}  "created"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Failure 401	{object} map[string]interface{}  "registry denied access to the image"
+// @Failure 404	{object} map[string]interface{}  "image not found"
+// @Failure 409	{object} map[string]interface{}  "name already in use"
 // @Router /containers/create [post]
 func createContainer(c *gin.Context) {
 
-	requestData, err := ginContextToBytes(c)
+	response, status, err := forwardRequestWithTimeout(c, pullTimeout)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	err = communication.WriteData(stream, requestData)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprint("Error sending request to another node:", err.Error())})
-		return
-	}
-
-	response := <-apiChan
-
-	if response.Err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": response.Err.Error()})
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
 	var result container.CreateResponse
 
-	json.Unmarshal(response.Data, &result)
+	json.Unmarshal(response.Payload, &result)
 
-	c.JSON(http.StatusOK, gin.H{"result": result})
+	c.JSON(status, gin.H{"result": result})
 }
 
 // @Summary inspects a Docker container by ID
@@ -172,88 +356,44 @@ func createContainer(c *gin.Context) {
 // @Produce  json
 // @Param id path string true "id"
 // @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 404	{object} map[string]interface{}  "container not found"
 // @Router /containers/:id [get]
 func inspectContainer(c *gin.Context) {
 
-	requestData, err := ginContextToBytes(c)
+	response, status, err := forwardRequest(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	err = communication.WriteData(stream, requestData)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprint("Error sending request to another node:", err.Error())})
-		return
-	}
-
-	response := <-apiChan
-
-	if response.Err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": response.Err.Error()})
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
 	var result types.ContainerJSON
 
-	json.Unmarshal(response.Data, &result)
+	json.Unmarshal(response.Payload, &result)
 
-	c.JSON(http.StatusOK, gin.H{"result": result})
+	c.JSON(status, gin.H{"result": result})
 }
 
 // @Summary deletes a Docker container by ID
 // @Accept  */*
 // @Produce  json
 // @Param id path string true "id"
+// @Param force query bool false "kill the container if it is running"
+// @Param removeVolumes query bool false "remove anonymous volumes of the container"
+// @Param removeLinks query bool false "remove the container links"
 // @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 400	{object} map[string]interface{}  "invalid query parameter"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Failure 409	{object} map[string]interface{}  "container is running"
 // @Router /containers/:id [delete]
 func deleteContainer(c *gin.Context) {
 
 	containerID := c.Param("id")
 
-	requestData, err := ginContextToBytes(c)
+	_, status, err := forwardRequest(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
-	err = communication.WriteData(stream, requestData)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprint("Error sending request to another node:", err.Error())})
-		return
-	}
-
-	response := <-apiChan
-
-	if response.Err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": response.Err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Container %s deleted", containerID)})
-}
-
-func GetFreePort() (port int, err error) {
-	var a *net.TCPAddr
-	if a, err = net.ResolveTCPAddr("tcp", "localhost:0"); err == nil {
-		var l *net.TCPListener
-		if l, err = net.ListenTCP("tcp", a); err == nil {
-			defer l.Close()
-			return l.Addr().(*net.TCPAddr).Port, nil
-		}
-	}
-	return
-}
-
-func Check(port int) (status bool, err error) {
-	host := ":" + strconv.Itoa(port)
-	server, err := net.Listen("tcp", host)
-
-	if err != nil {
-		return false, err
-	}
-
-	server.Close()
-
-	return true, nil
+	c.JSON(status, gin.H{"message": fmt.Sprintf("Container %s deleted", containerID)})
 }
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTransactionBytes(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	var got TransactionRequest
+
+	r := gin.New()
+	r.UseRawPath = true
+	r.UnescapePathValues = true
+
+	forward := func(c *gin.Context) {
+		data, err := transactionBytes(c, "1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, group := range []*gin.RouterGroup{&r.RouterGroup, r.Group("/peers/:peer")} {
+		group.GET("/containers/:id/logs", forward)
+		group.GET("/images/:id", forward)
+		group.GET("/containers/list", forward)
+	}
+
+	const peerID = "12D3KooWGRUVh9ZMaSmv5r2mReqWyTcqnVCBNxLp3W5nBHGbkH7Y"
+
+	tests := []struct {
+		name   string
+		uri    string
+		want   string
+		params gin.Params
+	}{
+		{"unscoped", "/containers/web/logs?tail=10", "/containers/web/logs?tail=10", gin.Params{{Key: "id", Value: "web"}}},
+		{"by alias", "/peers/db/containers/web/logs?tail=10", "/containers/web/logs?tail=10", gin.Params{{Key: "id", Value: "web"}}},
+		{"by peer ID", "/peers/" + peerID + "/containers/web/logs", "/containers/web/logs", gin.Params{{Key: "id", Value: "web"}}},
+		{"without params", "/peers/db/containers/list?all=true", "/containers/list?all=true", gin.Params{}},
+		{"encoded param", "/peers/db/images/library%2Fnginx", "/images/library%2Fnginx", gin.Params{{Key: "id", Value: "library/nginx"}}},
+		{"peer named like a route", "/peers/containers/containers/web/logs", "/containers/web/logs", gin.Params{{Key: "id", Value: "web"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			got = TransactionRequest{}
+
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.uri, nil))
+
+			if got.Uri != test.want {
+				t.Fatalf("got URI %q, want %q", got.Uri, test.want)
+			}
+			if got.Params == nil || !reflect.DeepEqual(*got.Params, test.params) {
+				t.Fatalf("got params %v, want %v", got.Params, test.params)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	p2p "github.com/jhonjoao/remote-containers/internal/libp2p"
+	"github.com/libp2p/go-libp2p/core/network"
+)
+
+// @Summary downloads a path of a Docker container as a tar archive
+// @Description The archive is streamed from the remote machine as the
+// @Description daemon produces it, so large files are never held in memory.
+// @Accept  */*
+// @Produce  application/x-tar
+// @Param id path string true "id"
+// @Param path query string true "file or directory inside the container"
+// @Success 200	{file} file  "tar archive"
+// @Failure 400	{object} map[string]interface{}  "path is missing"
+// @Failure 404	{object} map[string]interface{}  "container or path not found"
+// @Router /containers/:id/archive [get]
+func copyFromContainer(c *gin.Context) {
+
+	source := c.Query("path")
+	if source == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path is required"})
+		return
+	}
+
+	s, done, status, err := openArchive(c, communication.ArchiveStart{Path: source})
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer done()
+
+	name := path.Base(path.Clean(source))
+	if name == "/" || name == "." {
+		name = "archive"
+	}
+
+	// The headers are set with the first chunk so an error answered before
+	// it is not served as an attachment.
+	started := false
+	start := func() {
+		if !started {
+			c.Header("Content-Type", "application/x-tar")
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".tar"))
+			c.Status(http.StatusOK)
+			started = true
+		}
+	}
+
+	reader := bufio.NewReader(s)
+
+	for {
+		frame, err := communication.ReadFrame(reader)
+		if err != nil {
+			failArchive(c, started, http.StatusBadGateway, fmt.Errorf("archive stream closed: %w", err))
+			return
+		}
+
+		switch frame.Type {
+		case communication.FrameData:
+			start()
+			if _, err := c.Writer.Write(frame.Payload); err != nil {
+				s.Reset()
+				return
+			}
+
+		case communication.FrameArchiveEnd:
+			end, err := decodeArchiveEnd(frame)
+			switch {
+			case err != nil:
+				failArchive(c, started, http.StatusBadGateway, err)
+			case end.Error != "":
+				failArchive(c, started, end.Status, errors.New(end.Error))
+			default:
+				start()
+			}
+			return
+		}
+	}
+}
+
+// failArchive answers a failed download with its status code. Once the
+// archive started the connection is cut instead, as a truncated tar can
+// look complete.
+func failArchive(c *gin.Context, started bool, status int, err error) {
+
+	if started {
+		logger.Errorf("Archive download failed: %s", err)
+		abort(c)
+		return
+	}
+
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// @Summary extracts a tar archive into a path of a Docker container
+// @Description The request body is streamed to the remote machine and
+// @Description extracted while it is being uploaded, at the pace the daemon
+// @Description reads it.
+// @Accept  application/x-tar
+// @Produce  json
+// @Param id path string true "id"
+// @Param path query string true "directory inside the container to extract the archive into"
+// @Param noOverwriteDirNonDir query bool false "fail instead of replacing a directory with a file or the other way around"
+// @Param copyUIDGID query bool false "keep the owner of the files from the archive"
+// @Param archive body string true "tar archive"
+// @Success 200	{object} map[string]interface{}  "extracted"
+// @Failure 400	{object} map[string]interface{}  "path is missing or the archive is invalid"
+// @Failure 403	{object} map[string]interface{}  "the container file system is read-only"
+// @Failure 404	{object} map[string]interface{}  "container or path not found"
+// @Router /containers/:id/archive [put]
+func copyToContainer(c *gin.Context) {
+
+	start := communication.ArchiveStart{Path: c.Query("path"), Upload: true}
+	if start.Path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path is required"})
+		return
+	}
+
+	for name, option := range map[string]*bool{
+		"noOverwriteDirNonDir": &start.NoOverwriteDirNonDir,
+		"copyUIDGID":           &start.CopyUIDGID,
+	} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+
+		var err error
+		*option, err = strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s value %q, expected a boolean", name, value)})
+			return
+		}
+	}
+
+	s, done, status, err := openArchive(c, start)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer done()
+
+	// A write fails once the other node stops reading, e.g. because the
+	// daemon refused the archive, and its answer says why.
+	var readErr error
+	_, err = io.Copy(s, readerFunc(func(p []byte) (int, error) {
+		n, err := c.Request.Body.Read(p)
+		if err != nil && err != io.EOF {
+			readErr = err
+		}
+		return n, err
+	}))
+	if readErr != nil {
+		s.Reset()
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprint("failed to read request body: ", readErr)})
+		return
+	}
+	if err == nil {
+		s.CloseWrite()
+	}
+
+	s.SetReadDeadline(time.Now().Add(communication.RequestTimeout))
+
+	end, err := readArchiveEnd(bufio.NewReader(s))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	if end.Error != "" {
+		status := end.Status
+		if status < http.StatusBadRequest {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": end.Error})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"copied": gin.H{"id": c.Param("id"), "path": start.Path}})
+}
+
+// openArchive opens an archive stream to the peer the request is meant for
+// and sends start on it. The stream is reset if the HTTP client goes away
+// before done is called.
+func openArchive(c *gin.Context, start communication.ArchiveStart) (s network.Stream, done func(), status int, err error) {
+
+	remote, status, err := targetPeer(c)
+	if err != nil {
+		return nil, nil, status, err
+	}
+
+	start.ContainerID = c.Param("id")
+
+	s, err = peerManager.Host().NewStream(c.Request.Context(), remote.ID, communication.ArchiveProtocol)
+	if err != nil {
+		return nil, nil, http.StatusServiceUnavailable, fmt.Errorf("error opening archive stream to another node: %w", p2p.DialError(err))
+	}
+
+	err = communication.NewFrameWriter(s).WriteJSON(communication.FrameArchiveStart, start)
+	if err != nil {
+		s.Reset()
+		return nil, nil, http.StatusServiceUnavailable, fmt.Errorf("error sending archive request to another node: %w", err)
+	}
+
+	stop := context.AfterFunc(c.Request.Context(), func() { s.Reset() })
+	done = func() {
+		stop()
+		s.Close()
+	}
+
+	return s, done, http.StatusOK, nil
+}
+
+// readArchiveEnd skips to the frame that ends an archive stream.
+func readArchiveEnd(reader *bufio.Reader) (communication.ArchiveEnd, error) {
+
+	for {
+		frame, err := communication.ReadFrame(reader)
+		if err != nil {
+			return communication.ArchiveEnd{}, fmt.Errorf("archive stream closed: %w", err)
+		}
+
+		if frame.Type == communication.FrameArchiveEnd {
+			return decodeArchiveEnd(frame)
+		}
+	}
+}
+
+func decodeArchiveEnd(frame communication.Frame) (communication.ArchiveEnd, error) {
+
+	var end communication.ArchiveEnd
+	if err := json.Unmarshal(frame.Payload, &end); err != nil {
+		return communication.ArchiveEnd{}, errors.New("invalid archive end from the other node")
+	}
+
+	return end, nil
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
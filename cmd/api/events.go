@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+)
+
+// errEventsRejected is returned when the other node refused the
+// subscription itself, e.g. because of an invalid filter, so retrying it
+// would not help.
+var errEventsRejected = errors.New("events subscription rejected")
+
+// @Summary streams Docker events of the remote machine as Server-Sent Events
+// @Description Each event is sent with its Docker type as the SSE event name
+// @Description and its time and object ID as the SSE id, so reconnecting
+// @Description clients resume with Last-Event-ID without getting it twice.
+// @Description The subscription to the other node is re-established
+// @Description automatically when it drops.
+// @Accept  */*
+// @Produce  text/event-stream
+// @Param type query []string false "event type, e.g. container" collectionFormat(multi)
+// @Param event query []string false "event action, e.g. die, start, oom" collectionFormat(multi)
+// @Param container query []string false "container id or name" collectionFormat(multi)
+// @Param label query []string false "label or label=value" collectionFormat(multi)
+// @Param filter query []string false "other key=value event filters" collectionFormat(multi)
+// @Param since query string false "replay events since a timestamp"
+// @Success 200	{string} string  "event stream"
+// @Router /events [get]
+func dockerEvents(c *gin.Context) {
+
+	// The events at the time of the last one the client got are replayed
+	// by Docker, and skipped up to that one.
+	var last *docker.EventPosition
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" && c.Query("since") == "" {
+		position, err := docker.ParseEventPosition(lastEventID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		last = &position
+		setQuery(c, "since", position.Since())
+	}
+
+	// A missing peer is retried like a dropped subscription, but an unknown
+	// or ambiguous one never fixes itself.
+	if _, status, err := targetPeer(c); err != nil && status != http.StatusServiceUnavailable {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	backoff := docker.EventsMinBackoff
+
+	for {
+		remote, _, err := openStream(c)
+		if err == nil {
+			err = relayEvents(c, remote, &last, &backoff)
+			remote.close()
+		}
+
+		if c.Request.Context().Err() != nil {
+			return
+		}
+
+		sse.Encode(c.Writer, sse.Event{Event: "error", Data: err.Error()})
+		c.Writer.Flush()
+
+		if errors.Is(err, errEventsRejected) {
+			return
+		}
+
+		logger.Warnf("Events subscription dropped, retrying in %s: %s", backoff, err)
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, docker.EventsMaxBackoff)
+	}
+}
+
+// relayEvents writes the events of one subscription to the client and
+// moves the since query parameter along, so the next subscription resumes
+// after last, the last event sent.
+func relayEvents(c *gin.Context, remote *remoteStream, last **docker.EventPosition, backoff *time.Duration) error {
+
+	for {
+		var envelope communication.Envelope
+
+		select {
+		case envelope = <-remote.messages:
+		case <-c.Request.Context().Done():
+			return c.Request.Context().Err()
+		}
+
+		switch envelope.Kind {
+		case communication.KindEvent:
+			var message events.Message
+			if err := json.Unmarshal(envelope.Payload, &message); err != nil {
+				continue
+			}
+
+			if *last != nil && (*last).Seen(message) {
+				continue
+			}
+
+			position := docker.PositionOf(message)
+			*last = &position
+			setQuery(c, "since", position.Since())
+			*backoff = docker.EventsMinBackoff
+
+			err := sse.Encode(c.Writer, sse.Event{
+				Id:    position.String(),
+				Event: string(message.Type),
+				Data:  json.RawMessage(envelope.Payload),
+			})
+			if err != nil {
+				return err
+			}
+			c.Writer.Flush()
+
+		case communication.KindError:
+			remote.finished = true
+			if envelope.Status == http.StatusBadRequest {
+				return fmt.Errorf("%w: %s", errEventsRejected, envelope.Error)
+			}
+			return errors.New(envelope.Error)
+
+		default:
+			remote.finished = true
+			return errors.New("event stream ended by the other node")
+		}
+	}
+}
+
+func setQuery(c *gin.Context, key string, value string) {
+	query := c.Request.URL.Query()
+	query.Set(key, value)
+	c.Request.URL.RawQuery = query.Encode()
+	c.Request.RequestURI = c.Request.URL.RequestURI()
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	p2p "github.com/jhonjoao/remote-containers/internal/libp2p"
+)
+
+// WebSocket channels of an exec session. Every binary message starts with
+// the channel byte, like the Kubernetes channel protocol.
+// A stdin message carrying only the channel byte closes stdin.
+const (
+	execChannelStdin  byte = 0
+	execChannelStdout byte = 1
+	execChannelStderr byte = 2
+	execChannelStatus byte = 3
+	execChannelResize byte = 4
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  communication.StreamChunkSize,
+	WriteBufferSize: communication.StreamChunkSize,
+}
+
+// @Summary opens an interactive exec session in a Docker container
+// @Description Upgrades to a WebSocket. Binary messages start with a channel
+// @Description byte: 0 stdin, 1 stdout, 2 stderr, 3 exit status (JSON), 4 resize
+// @Description (JSON with height and width). A stdin message without data closes stdin.
+// @Param id path string true "id"
+// @Param cmd query []string true "command and arguments" collectionFormat(multi)
+// @Param tty query bool false "allocate a TTY"
+// @Param env query []string false "KEY=value environment variables" collectionFormat(multi)
+// @Param user query string false "user to run the command as"
+// @Param workingDir query string false "working directory of the command"
+// @Param height query int false "initial TTY height"
+// @Param width query int false "initial TTY width"
+// @Success 101 {string} string "switching protocols"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Failure 502	{object} map[string]interface{}  "could not reach the other node"
+// @Router /containers/:id/exec [get]
+func execContainer(c *gin.Context) {
+
+	start := communication.ExecStart{
+		ContainerID: c.Param("id"),
+		Cmd:         c.QueryArray("cmd"),
+		Env:         c.QueryArray("env"),
+		User:        c.Query("user"),
+		WorkingDir:  c.Query("workingDir"),
+	}
+
+	if len(start.Cmd) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cmd is required"})
+		return
+	}
+
+	var err error
+	if value := c.Query("tty"); value != "" {
+		start.Tty, err = strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid value %q for query parameter tty", value)})
+			return
+		}
+	}
+
+	height, _ := strconv.ParseUint(c.Query("height"), 10, 32)
+	width, _ := strconv.ParseUint(c.Query("width"), 10, 32)
+	start.Height = uint(height)
+	start.Width = uint(width)
+
+	remote, status, err := targetPeer(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	s, err := peerManager.Host().NewStream(c.Request.Context(), remote.ID, communication.ExecProtocol)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprint("Error opening exec stream to another node: ", p2p.DialError(err).Error())})
+		return
+	}
+	defer s.Close()
+
+	writer := communication.NewFrameWriter(s)
+
+	err = writer.WriteJSON(communication.FrameExecStart, start)
+	if err != nil {
+		s.Reset()
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprint("Error sending exec request to another node: ", err.Error())})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		s.Reset()
+		return
+	}
+	defer conn.Close()
+
+	go forwardWebSocketInput(conn, writer)
+
+	exit := relayExecOutput(bufio.NewReader(s), conn)
+
+	exitStatus, _ := json.Marshal(exit)
+	conn.WriteMessage(websocket.BinaryMessage, append([]byte{execChannelStatus}, exitStatus...))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// relayExecOutput sends the output frames of the exec stream to the
+// WebSocket until the remote node reports the exit of the process.
+func relayExecOutput(reader *bufio.Reader, conn *websocket.Conn) communication.ExecExit {
+
+	for {
+		frame, err := communication.ReadFrame(reader)
+		if err != nil {
+			return communication.ExecExit{ExitCode: -1, Error: fmt.Sprint("exec stream closed: ", err.Error())}
+		}
+
+		var channel byte
+		switch frame.Type {
+		case communication.FrameStdout:
+			channel = execChannelStdout
+		case communication.FrameStderr:
+			channel = execChannelStderr
+		case communication.FrameExit:
+			var exit communication.ExecExit
+			if err := json.Unmarshal(frame.Payload, &exit); err != nil {
+				return communication.ExecExit{ExitCode: -1, Error: "invalid exit status from the other node"}
+			}
+			return exit
+		default:
+			continue
+		}
+
+		err = conn.WriteMessage(websocket.BinaryMessage, append([]byte{channel}, frame.Payload...))
+		if err != nil {
+			return communication.ExecExit{ExitCode: -1, Error: err.Error()}
+		}
+	}
+}
+
+// forwardWebSocketInput turns the stdin and resize messages of the client
+// into frames on the exec stream.
+func forwardWebSocketInput(conn *websocket.Conn, writer *communication.FrameWriter) {
+
+	for {
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			writer.WriteFrame(communication.Frame{Type: communication.FrameStdinClose})
+			return
+		}
+
+		if messageType != websocket.BinaryMessage || len(message) == 0 {
+			continue
+		}
+
+		switch message[0] {
+		case execChannelStdin:
+			if len(message) == 1 {
+				err = writer.WriteFrame(communication.Frame{Type: communication.FrameStdinClose})
+			} else {
+				err = writer.WriteFrame(communication.Frame{Type: communication.FrameStdin, Payload: message[1:]})
+			}
+		case execChannelResize:
+			var size communication.ExecResize
+			if json.Unmarshal(message[1:], &size) == nil {
+				err = writer.WriteJSON(communication.FrameResize, size)
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+)
+
+// webSocketPair returns the server and client ends of a WebSocket.
+func webSocketPair(t *testing.T) (server, client *websocket.Conn) {
+	t.Helper()
+
+	accepted := make(chan *websocket.Conn, 1)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		accepted <- conn
+	}))
+	t.Cleanup(httpServer.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	server = <-accepted
+	t.Cleanup(func() { server.Close() })
+
+	return server, client
+}
+
+func frames(t *testing.T, list ...communication.Frame) *bufio.Reader {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	for _, frame := range list {
+		if err := communication.WriteFrame(&buffer, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return bufio.NewReader(&buffer)
+}
+
+func TestRelayExecOutput(t *testing.T) {
+
+	server, client := webSocketPair(t)
+
+	exit := relayExecOutput(frames(t,
+		communication.Frame{Type: communication.FrameStdout, Payload: []byte("out")},
+		communication.Frame{Type: communication.FrameData, Payload: []byte("ignored")},
+		communication.Frame{Type: communication.FrameStderr, Payload: []byte("err")},
+		communication.Frame{Type: communication.FrameExit, Payload: []byte(`{"exitCode":2}`)},
+		communication.Frame{Type: communication.FrameStdout, Payload: []byte("after exit")},
+	), server)
+
+	if exit != (communication.ExecExit{ExitCode: 2}) {
+		t.Fatalf("got exit %+v, want exit code 2", exit)
+	}
+
+	for _, want := range []string{"\x01out", "\x02err"} {
+		messageType, message, err := client.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if messageType != websocket.BinaryMessage || string(message) != want {
+			t.Fatalf("got message %q of type %d, want %q", message, messageType, want)
+		}
+	}
+}
+
+func TestRelayExecOutputFailures(t *testing.T) {
+
+	tests := map[string]struct {
+		output *bufio.Reader
+		error  string
+	}{
+		"stream closed": {
+			frames(t, communication.Frame{Type: communication.FrameStdout, Payload: []byte("out")}),
+			"exec stream closed",
+		},
+		"invalid exit": {
+			frames(t, communication.Frame{Type: communication.FrameExit, Payload: []byte("{")}),
+			"invalid exit status from the other node",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			server, _ := webSocketPair(t)
+
+			exit := relayExecOutput(test.output, server)
+			if exit.ExitCode != -1 || !strings.Contains(exit.Error, test.error) {
+				t.Fatalf("got exit %+v, want exit code -1 and an error about %q", exit, test.error)
+			}
+		})
+	}
+}
+
+func TestForwardWebSocketInput(t *testing.T) {
+
+	server, client := webSocketPair(t)
+
+	input, output := io.Pipe()
+	go func() {
+		forwardWebSocketInput(server, communication.NewFrameWriter(output))
+		output.Close()
+	}()
+
+	for _, message := range []struct {
+		messageType int
+		data        string
+	}{
+		{websocket.BinaryMessage, "\x00ls\n"},
+		{websocket.TextMessage, "\x00ignored"},
+		{websocket.BinaryMessage, "\x04{\"height\":24,\"width\":80}"},
+		{websocket.BinaryMessage, "\x04{"},
+		{websocket.BinaryMessage, "\x09unknown channel"},
+		{websocket.BinaryMessage, "\x00"},
+	} {
+		if err := client.WriteMessage(message.messageType, []byte(message.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Close()
+
+	want := []communication.Frame{
+		{Type: communication.FrameStdin, Payload: []byte("ls\n")},
+		{Type: communication.FrameResize, Payload: []byte(`{"height":24,"width":80}`)},
+		{Type: communication.FrameStdinClose},
+		// The client going away closes stdin too.
+		{Type: communication.FrameStdinClose},
+	}
+
+	reader := bufio.NewReader(input)
+	for _, wanted := range want {
+		frame, err := communication.ReadFrame(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if frame.Type != wanted.Type || !bytes.Equal(frame.Payload, wanted.Payload) {
+			t.Fatalf("got frame %d %q, want %d %q", frame.Type, frame.Payload, wanted.Type, wanted.Payload)
+		}
+	}
+
+	if _, err := communication.ReadFrame(reader); err != io.EOF {
+		t.Fatalf("got %v after the last frame, want the end of the stream", err)
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/go-units"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// pullTimeout bounds the requests that may have to pull an image first.
+const pullTimeout = 10 * time.Minute
+
+// @Summary pulls an image on the remote machine
+// @Description Streams the progress messages of the daemon as
+// @Description newline-delimited JSON, or as Server-Sent Events when the
+// @Description client accepts text/event-stream. Registry credentials are
+// @Description only forwarded to the remote Docker daemon.
+// @Accept json
+// @Produce json
+// @Produce text/event-stream
+// @Param data body docker.PullRequest true "image to pull"
+// @Success 200	{object} docker.PullProgress  "progress messages"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Failure 401	{object} map[string]interface{}  "registry denied access"
+// @Failure 404	{object} map[string]interface{}  "image not found"
+// @Router /images/pull [post]
+func pullImage(c *gin.Context) {
+
+	remote, status, err := openStream(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer remote.close()
+
+	if wantsEventStream(c) {
+		err := remote.relay(c, "text/event-stream", func(w io.Writer, chunk []byte) bool {
+			return sse.Encode(w, sse.Event{Event: "progress", Data: string(bytes.TrimSpace(chunk))}) == nil
+		})
+		if err != nil {
+			sse.Encode(c.Writer, sse.Event{Event: "error", Data: err.Error()})
+		}
+		return
+	}
+
+	err = remote.relay(c, "application/x-ndjson", func(w io.Writer, chunk []byte) bool {
+		_, err := w.Write(chunk)
+		return err == nil
+	})
+
+	// Ending the response cleanly would hide that the pull failed
+	// halfway.
+	if err != nil {
+		abort(c)
+	}
+}
+
+// @Summary lists the Docker images of the remote machine
+// @Accept  */*
+// @Produce  json
+// @Param all query bool false "include intermediate images"
+// @Param filter query []string false "key=value filters, e.g. dangling=true or reference=nginx" collectionFormat(multi)
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 400	{object} map[string]interface{}  "invalid filter"
+// @Router /images/list [get]
+func listImages(c *gin.Context) {
+
+	response, status, err := forwardRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result []image.Summary
+
+	json.Unmarshal(response.Payload, &result)
+
+	c.JSON(status, gin.H{"images": result})
+}
+
+// @Summary inspects a Docker image
+// @Description References containing "/" must be URL encoded, e.g. library%2Fnginx:latest.
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "image id or reference"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 404	{object} map[string]interface{}  "image not found"
+// @Router /images/:id [get]
+func inspectImage(c *gin.Context) {
+
+	response, status, err := forwardRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result types.ImageInspect
+
+	json.Unmarshal(response.Payload, &result)
+
+	c.JSON(status, gin.H{"result": result})
+}
+
+// @Summary removes a Docker image
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "image id or reference"
+// @Param force query bool false "remove the image even if containers use it"
+// @Param noprune query bool false "keep untagged parent images"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 404	{object} map[string]interface{}  "image not found"
+// @Failure 409	{object} map[string]interface{}  "image is in use"
+// @Router /images/:id [delete]
+func removeImage(c *gin.Context) {
+
+	response, status, err := forwardRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result []image.DeleteResponse
+
+	json.Unmarshal(response.Payload, &result)
+
+	c.JSON(status, gin.H{"deleted": result})
+}
+
+// @Summary tags a Docker image
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "image id or reference"
+// @Param repo query string true "repository of the new tag"
+// @Param tag query string false "tag name, latest by default"
+// @Success 201	{object} map[string]interface{}  "created"
+// @Failure 400	{object} map[string]interface{}  "invalid reference"
+// @Failure 404	{object} map[string]interface{}  "image not found"
+// @Router /images/:id/tag [post]
+func tagImage(c *gin.Context) {
+
+	response, status, err := forwardRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result string
+
+	json.Unmarshal(response.Payload, &result)
+
+	c.JSON(status, gin.H{"tag": result})
+}
+
+// @Summary removes unused Docker images
+// @Accept  */*
+// @Produce  json
+// @Param all query bool false "remove every image without containers, not only dangling ones"
+// @Param filter query []string false "key=value filters, e.g. until=24h or label=env=dev" collectionFormat(multi)
+// @Success 200	{object} map[string]interface{}  "deleted images and reclaimed space"
+// @Failure 400	{object} map[string]interface{}  "invalid filter"
+// @Router /images/prune [post]
+func pruneImages(c *gin.Context) {
+
+	response, status, err := forwardRequestWithTimeout(c, pullTimeout)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result types.ImagesPruneReport
+
+	json.Unmarshal(response.Payload, &result)
+
+	c.JSON(status, gin.H{
+		"deleted":             result.ImagesDeleted,
+		"spaceReclaimed":      result.SpaceReclaimed,
+		"spaceReclaimedHuman": units.HumanSize(float64(result.SpaceReclaimed)),
+	})
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/gin-gonic/gin"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+)
+
+// @Summary starts a Docker container
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200	{object} map[string]interface{}  "state of the container"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Router /containers/:id/start [post]
+func startContainer(c *gin.Context) {
+	forwardLifecycleAction(c, communication.RequestTimeout)
+}
+
+// @Summary stops a Docker container
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "id"
+// @Param timeout query int false "seconds to wait before killing the container"
+// @Success 200	{object} map[string]interface{}  "state of the container"
+// @Failure 400	{object} map[string]interface{}  "invalid timeout"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Router /containers/:id/stop [post]
+func stopContainer(c *gin.Context) {
+	timeout, err := stopTimeout(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	forwardLifecycleAction(c, timeout)
+}
+
+// @Summary restarts a Docker container
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "id"
+// @Param timeout query int false "seconds to wait before killing the container"
+// @Success 200	{object} map[string]interface{}  "state of the container"
+// @Failure 400	{object} map[string]interface{}  "invalid timeout"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Router /containers/:id/restart [post]
+func restartContainer(c *gin.Context) {
+	timeout, err := stopTimeout(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	forwardLifecycleAction(c, timeout)
+}
+
+// @Summary sends a signal to a Docker container
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "id"
+// @Param signal query string false "signal to send, SIGKILL by default"
+// @Success 200	{object} map[string]interface{}  "state of the container"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Failure 409	{object} map[string]interface{}  "container is not running"
+// @Router /containers/:id/kill [post]
+func killContainer(c *gin.Context) {
+	forwardLifecycleAction(c, communication.RequestTimeout)
+}
+
+// @Summary pauses all processes of a Docker container
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200	{object} map[string]interface{}  "state of the container"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Failure 409	{object} map[string]interface{}  "container is not running"
+// @Router /containers/:id/pause [post]
+func pauseContainer(c *gin.Context) {
+	forwardLifecycleAction(c, communication.RequestTimeout)
+}
+
+// @Summary resumes all processes of a paused Docker container
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "id"
+// @Success 200	{object} map[string]interface{}  "state of the container"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Failure 409	{object} map[string]interface{}  "container is not paused"
+// @Router /containers/:id/unpause [post]
+func unpauseContainer(c *gin.Context) {
+	forwardLifecycleAction(c, communication.RequestTimeout)
+}
+
+func forwardLifecycleAction(c *gin.Context, timeout time.Duration) {
+
+	response, status, err := forwardRequestWithTimeout(c, timeout)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result types.ContainerState
+
+	json.Unmarshal(response.Payload, &result)
+
+	c.JSON(status, gin.H{"state": result})
+}
+
+// stopTimeout gives the remote node the time the container is allowed to
+// take to stop on top of the usual request timeout. A negative timeout,
+// which Docker takes as waiting forever, is refused.
+func stopTimeout(c *gin.Context) (time.Duration, error) {
+	value := c.Query("timeout")
+	if value == "" {
+		return communication.RequestTimeout, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("invalid value %q for query parameter timeout, expected seconds >= 0", value)
+	}
+
+	return communication.RequestTimeout + time.Duration(seconds)*time.Second, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"io"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary streams the logs of a Docker container
+// @Description Sends the logs as a chunked text/plain response, or as
+// @Description Server-Sent Events when the client accepts text/event-stream.
+// @Description When the logs fail after they started, the event stream ends
+// @Description with an error event and a plain response is cut off.
+// @Accept  */*
+// @Produce  plain
+// @Produce  text/event-stream
+// @Param id path string true "id"
+// @Param follow query bool false "keep streaming new log lines"
+// @Param tail query string false "number of lines to show from the end, or all"
+// @Param since query string false "show logs since a timestamp or relative time (e.g. 10m)"
+// @Param until query string false "show logs before a timestamp or relative time"
+// @Param timestamps query bool false "prefix every line with its timestamp"
+// @Param stdout query bool false "include stdout (default true unless stderr is set)"
+// @Param stderr query bool false "include stderr (default true unless stdout is set)"
+// @Success 200	{string} string  "log output"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Router /containers/:id/logs [get]
+func containerLogs(c *gin.Context) {
+
+	remote, status, err := openStream(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer remote.close()
+
+	if wantsEventStream(c) {
+		err := remote.relay(c, "text/event-stream", func(w io.Writer, chunk []byte) bool {
+			return sse.Encode(w, sse.Event{Event: "log", Data: string(chunk)}) == nil
+		})
+		if err != nil {
+			sse.Encode(c.Writer, sse.Event{Event: "error", Data: err.Error()})
+		}
+		return
+	}
+
+	err = remote.relay(c, "text/plain; charset=utf-8", func(w io.Writer, chunk []byte) bool {
+		_, err := w.Write(chunk)
+		return err == nil
+	})
+
+	// A response cut short could pass for a complete one, so the
+	// connection is closed instead of ending it cleanly.
+	if err != nil {
+		abort(c)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary creates a Docker network
+// @Accept json
+// @Produce json
+// @Param data body docker.NetworkCreateRequest true "network options"
+// @Success 201	{object} map[string]interface{}  "created"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Failure 409	{object} map[string]interface{}  "name already in use"
+// @Router /networks/create [post]
+func createNetwork(c *gin.Context) {
+	relayResponse(c, "result")
+}
+
+// @Summary lists Docker networks
+// @Accept  */*
+// @Produce  json
+// @Param filter query []string false "key=value filters, e.g. driver=bridge or name=backend" collectionFormat(multi)
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 400	{object} map[string]interface{}  "invalid filter"
+// @Router /networks/list [get]
+func listNetworks(c *gin.Context) {
+	relayResponse(c, "networks")
+}
+
+// @Summary inspects a Docker network
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "network id or name"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 404	{object} map[string]interface{}  "network not found"
+// @Router /networks/:id [get]
+func inspectNetwork(c *gin.Context) {
+	relayResponse(c, "result")
+}
+
+// @Summary removes a Docker network
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "network id or name"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 404	{object} map[string]interface{}  "network not found"
+// @Failure 409	{object} map[string]interface{}  "network has active endpoints"
+// @Router /networks/:id [delete]
+func removeNetwork(c *gin.Context) {
+	relayResponse(c, "message")
+}
+
+// @Summary removes unused Docker networks
+// @Accept  */*
+// @Produce  json
+// @Param filter query []string false "key=value filters, e.g. until=24h" collectionFormat(multi)
+// @Success 200	{object} map[string]interface{}  "deleted networks"
+// @Failure 400	{object} map[string]interface{}  "invalid filter"
+// @Router /networks/prune [post]
+func pruneNetworks(c *gin.Context) {
+	relayResponse(c, "result")
+}
+
+// @Summary connects a container to a Docker network
+// @Accept json
+// @Produce json
+// @Param id path string true "network id or name"
+// @Param data body docker.NetworkConnectRequest true "container to connect"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Failure 404	{object} map[string]interface{}  "network or container not found"
+// @Router /networks/:id/connect [post]
+func connectNetwork(c *gin.Context) {
+	relayResponse(c, "message")
+}
+
+// @Summary disconnects a container from a Docker network
+// @Accept json
+// @Produce json
+// @Param id path string true "network id or name"
+// @Param data body docker.NetworkDisconnectRequest true "container to disconnect"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Failure 404	{object} map[string]interface{}  "network or container not found"
+// @Router /networks/:id/disconnect [post]
+func disconnectNetwork(c *gin.Context) {
+	relayResponse(c, "message")
+}
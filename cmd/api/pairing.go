@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhonjoao/remote-containers/internal/pairing"
+	"github.com/jhonjoao/remote-containers/internal/trust"
+)
+
+type IssueTokenRequest struct {
+	// Name and Role are those the joining node is authorized with.
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// Ttl is how long the token can be used, e.g. "10m". It defaults to
+	// 10 minutes.
+	Ttl string `json:"ttl"`
+}
+
+type JoinRequest struct {
+	Token string `json:"token"`
+	// Name and Role are those the node that issued the token is authorized
+	// with here. Role defaults to viewer.
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// localOnly rejects the requests that don't come from a loopback address.
+// The address of the connection is used rather than gin's ClientIP, which
+// trusts headers set by the client. Web pages open in a browser on the
+// same machine are turned away too: they either send a foreign Origin, a
+// foreign Host after rebinding their DNS name to 127.0.0.1, or a body that
+// is not JSON, as they can't set that content type without a preflight.
+func localOnly(c *gin.Context) {
+
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "pairing is only available from the machine the node runs on"})
+		return
+	}
+
+	if !isLocalHost(c.Request.Host) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("pairing is not available through host %q", c.Request.Host)})
+		return
+	}
+
+	if origin := c.GetHeader("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !isLocalHost(u.Host) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("pairing is not available from origin %q", origin)})
+			return
+		}
+	}
+
+	if c.Request.ContentLength != 0 {
+		mediaType, _, err := mime.ParseMediaType(c.ContentType())
+		if err != nil || mediaType != gin.MIMEJSON {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "expected a body of type " + gin.MIMEJSON})
+			return
+		}
+	}
+
+	c.Next()
+}
+
+// isLocalHost reports whether host, with or without a port, names this
+// machine.
+func isLocalHost(host string) bool {
+
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.Trim(host, "[]")
+
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// @Summary issues a one-time pairing token
+// @Description The token holds the address of this node and a secret. The
+// @Description node that joins with it is authorized on this one, and
+// @Description authorizes it back.
+// @Accept  json
+// @Produce  json
+// @Param data body IssueTokenRequest false "name and role of the joining node, lifetime of the token"
+// @Success 201	{object} pairing.Token  "token"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Failure 403	{object} map[string]interface{}  "not sent from this machine"
+// @Failure 415	{object} map[string]interface{}  "body is not JSON"
+// @Router /pairing/tokens [post]
+func issuePairingToken(c *gin.Context) {
+
+	var request IssueTokenRequest
+	if c.Request.ContentLength != 0 {
+		err := c.ShouldBindJSON(&request)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var ttl time.Duration
+	if request.Ttl != "" {
+		var err error
+		ttl, err = time.ParseDuration(request.Ttl)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid ttl %q", request.Ttl)})
+			return
+		}
+	}
+
+	token, err := pairings.Issue(request.Name, request.Role, ttl)
+	if errors.Is(err, pairing.ErrNoAddress) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// @Summary lists the pairing tokens that can still be used
+// @Accept  */*
+// @Produce  json
+// @Success 200	{object} map[string][]pairing.Token  "tokens, without the token string"
+// @Failure 403	{object} map[string]interface{}  "not sent from this machine"
+// @Router /pairing/tokens [get]
+func listPairingTokens(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tokens": pairings.List()})
+}
+
+// @Summary revokes a pairing token
+// @Accept  */*
+// @Produce  json
+// @Param id path string true "token ID"
+// @Success 204  "revoked"
+// @Failure 403	{object} map[string]interface{}  "not sent from this machine"
+// @Failure 404	{object} map[string]interface{}  "no such token"
+// @Router /pairing/tokens/:id [delete]
+func revokePairingToken(c *gin.Context) {
+
+	err := pairings.Revoke(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary joins the node that issued a pairing token
+// @Description Both nodes authorize each other for good, and this node
+// @Description connects to the other one.
+// @Accept  json
+// @Produce  json
+// @Param data body JoinRequest true "token, alias and role of the other node"
+// @Success 200	{object} trust.Entry  "the other node"
+// @Failure 400	{object} map[string]interface{}  "invalid token or role"
+// @Failure 403	{object} map[string]interface{}  "token refused, or not sent from this machine"
+// @Failure 502	{object} map[string]interface{}  "other node unreachable"
+// @Failure 415	{object} map[string]interface{}  "body is not JSON"
+// @Router /pairing/join [post]
+func joinPeer(c *gin.Context) {
+
+	var request JoinRequest
+	err := c.ShouldBindJSON(&request)
+	if err == nil && request.Token == "" {
+		err = errors.New("missing token")
+	}
+	if err == nil {
+		err = trust.ValidateRole(request.Role)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := pairings.Join(context.Background(), request.Token, request.Name, request.Role)
+	switch {
+	case errors.Is(err, pairing.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, pairing.ErrRefused):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, entry)
+	}
+}
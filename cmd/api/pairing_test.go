@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLocalOnly(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/pairing/tokens", localOnly, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.POST("/pairing/join", localOnly, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	const join = `{"token":"attacker","role":"admin"}`
+
+	tests := []struct {
+		name        string
+		method      string
+		remote      string
+		host        string
+		header      map[string]string
+		body        string
+		contentType string
+		status      int
+	}{
+		{"loopback", http.MethodGet, "127.0.0.1:50000", "localhost:8080", nil, "", "", http.StatusOK},
+		{"ipv6 loopback", http.MethodGet, "[::1]:50000", "[::1]:8080", nil, "", "", http.StatusOK},
+		{"loopback host", http.MethodGet, "127.0.0.1:50000", "127.0.0.1:8080", nil, "", "", http.StatusOK},
+		{"other machine", http.MethodGet, "192.0.2.10:50000", "localhost:8080", nil, "", "", http.StatusForbidden},
+		{"forged header", http.MethodGet, "192.0.2.10:50000", "localhost:8080", map[string]string{"X-Forwarded-For": "127.0.0.1"}, "", "", http.StatusForbidden},
+		{"rebound host", http.MethodGet, "127.0.0.1:50000", "evil.example:8080", nil, "", "", http.StatusForbidden},
+		{"json join", http.MethodPost, "127.0.0.1:50000", "localhost:8080", nil, join, "application/json; charset=utf-8", http.StatusOK},
+		{"local origin", http.MethodPost, "127.0.0.1:50000", "localhost:8080", map[string]string{"Origin": "http://localhost:8080"}, join, "application/json", http.StatusOK},
+		{"cross-origin join", http.MethodPost, "127.0.0.1:50000", "localhost:8080", map[string]string{"Origin": "https://evil.example"}, join, "application/json", http.StatusForbidden},
+		{"cross-origin text join", http.MethodPost, "127.0.0.1:50000", "localhost:8080", map[string]string{"Origin": "https://evil.example"}, join, "text/plain", http.StatusForbidden},
+		{"opaque origin", http.MethodPost, "127.0.0.1:50000", "localhost:8080", map[string]string{"Origin": "null"}, join, "application/json", http.StatusForbidden},
+		{"text join", http.MethodPost, "127.0.0.1:50000", "localhost:8080", nil, join, "text/plain", http.StatusUnsupportedMediaType},
+		{"form join", http.MethodPost, "127.0.0.1:50000", "localhost:8080", nil, join, "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"untyped join", http.MethodPost, "127.0.0.1:50000", "localhost:8080", nil, join, "", http.StatusUnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			path := "/pairing/tokens"
+			if test.method == http.MethodPost {
+				path = "/pairing/join"
+			}
+
+			request := httptest.NewRequest(test.method, path, strings.NewReader(test.body))
+			request.RemoteAddr = test.remote
+			request.Host = test.host
+			for key, value := range test.header {
+				request.Header.Set(key, value)
+			}
+			if test.contentType != "" {
+				request.Header.Set("Content-Type", test.contentType)
+			}
+
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("got status %d, want %d", recorder.Code, test.status)
+			}
+		})
+	}
+}
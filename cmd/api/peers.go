@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhonjoao/remote-containers/internal/peers"
+)
+
+type PeerInfo struct {
+	Id    string `json:"id"`
+	Alias string `json:"alias,omitempty"`
+	// Role is what the peer may do on this node, admin or viewer.
+	Role string `json:"role"`
+	// Address is the one the connection to the peer uses, Addrs all those
+	// the peer is known to listen on.
+	Address     string    `json:"address"`
+	Addrs       []string  `json:"addrs"`
+	LatencyMs   float64   `json:"latencyMs"`
+	ConnectedAt time.Time `json:"connectedAt"`
+}
+
+func peerInfo(remote *peers.Peer) PeerInfo {
+
+	info := PeerInfo{
+		Id:          remote.ID.String(),
+		Alias:       peerManager.Alias(remote.ID),
+		Role:        remote.Role,
+		Address:     remote.RemoteAddr(),
+		Addrs:       []string{},
+		LatencyMs:   float64(peerManager.Latency(remote.ID).Microseconds()) / 1000,
+		ConnectedAt: remote.ConnectedAt,
+	}
+
+	for _, addr := range peerManager.Host().Peerstore().Addrs(remote.ID) {
+		info.Addrs = append(info.Addrs, addr.String())
+	}
+
+	return info
+}
+
+// @Summary lists the connected peers
+// @Description Every other route can be sent to one of them by prefixing it
+// @Description with /peers/{id or alias}, e.g. /peers/db/containers/list.
+// @Accept  */*
+// @Produce  json
+// @Success 200	{object} map[string][]PeerInfo  "connected peers"
+// @Router /peers [get]
+func listPeers(c *gin.Context) {
+
+	list := []PeerInfo{}
+	for _, remote := range peerManager.List() {
+		list = append(list, peerInfo(remote))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"peers": list})
+}
+
+// @Summary shows a connected peer
+// @Accept  */*
+// @Produce  json
+// @Param peer path string true "peer ID or alias"
+// @Success 200	{object} PeerInfo  "peer"
+// @Failure 404	{object} map[string]interface{}  "peer not connected"
+// @Router /peers/:peer [get]
+func inspectPeer(c *gin.Context) {
+
+	remote, status, err := targetPeer(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, peerInfo(remote))
+}
+
+// @Summary lists the nodes found on the local network that are not connected
+// @Description Nodes are found with mDNS. Those advertising the same cluster
+// @Description name have inCluster set.
+// @Accept  */*
+// @Produce  json
+// @Success 200	{object} map[string][]peers.Discovered  "discovered peers"
+// @Router /discovery [get]
+func discoveredPeers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"peers": peerManager.Discovered()})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary resource usage of a Docker container
+// @Description Returns one sample, or with stream=true keeps sending samples
+// @Description as newline-delimited JSON (Server-Sent Events when the client
+// @Description accepts text/event-stream). Each sample has a computed summary
+// @Description and, with raw=true, Docker's own stats.
+// @Accept  */*
+// @Produce  json
+// @Produce  text/event-stream
+// @Param id path string true "id"
+// @Param stream query bool false "keep streaming samples"
+// @Param raw query bool false "include Docker's raw stats"
+// @Success 200	{object} docker.StatsSample  "stats sample"
+// @Failure 404	{object} map[string]interface{}  "container not found"
+// @Router /containers/:id/stats [get]
+func containerStats(c *gin.Context) {
+
+	if stream, _ := strconv.ParseBool(c.Query("stream")); !stream {
+		relayResponse(c, "stats")
+		return
+	}
+
+	remote, status, err := openStream(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer remote.close()
+
+	if wantsEventStream(c) {
+		err := remote.relay(c, "text/event-stream", func(w io.Writer, chunk []byte) bool {
+			return sse.Encode(w, sse.Event{Event: "stats", Data: string(bytes.TrimSpace(chunk))}) == nil
+		})
+		if err != nil {
+			sse.Encode(c.Writer, sse.Event{Event: "error", Data: err.Error()})
+		}
+		return
+	}
+
+	err = remote.relay(c, "application/x-ndjson", func(w io.Writer, chunk []byte) bool {
+		_, err := w.Write(chunk)
+		return err == nil
+	})
+
+	// The stream is only cut short by an error, and the client has to be
+	// able to tell.
+	if err != nil {
+		abort(c)
+	}
+}
+
+// @Summary resource usage of all running containers
+// @Accept  */*
+// @Produce  json
+// @Success 200	{object} docker.AggregateStats  "per container summaries and totals"
+// @Router /stats [get]
+func allStats(c *gin.Context) {
+	relayResponse(c, "stats")
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhonjoao/remote-containers/internal/peers"
+)
+
+type NodeStatus struct {
+	PeerId string `json:"peerId"`
+	// ListenAddrs are the addresses the libp2p node is bound to.
+	ListenAddrs []string `json:"listenAddrs"`
+	// Multiaddrs are the addresses other nodes can connect to this one
+	// with.
+	Multiaddrs []string `json:"multiaddrs"`
+	// Api is the address the HTTP API is bound to.
+	Api            string   `json:"api"`
+	ConnectedPeers []string `json:"connectedPeers"`
+	// Supervised are the peers this node dialed and reconnects to when
+	// their connection drops.
+	Supervised []peers.Supervision `json:"supervised"`
+	// Events are the latest connections, disconnections and failed dials.
+	Events []peers.Event `json:"events"`
+}
+
+// @Summary shows the bound addresses and connections of this node
+// @Accept  */*
+// @Produce  json
+// @Success 200	{object} NodeStatus  "status of the node"
+// @Router /status [get]
+func nodeStatus(c *gin.Context) {
+
+	status := NodeStatus{
+		ListenAddrs:    []string{},
+		Multiaddrs:     []string{},
+		ConnectedPeers: []string{},
+		Supervised:     []peers.Supervision{},
+		Events:         []peers.Event{},
+	}
+
+	if apiListener != nil {
+		status.Api = apiListener.Addr().String()
+	}
+
+	if peerManager != nil {
+		h := peerManager.Host()
+		status.PeerId = h.ID().String()
+
+		for _, addr := range h.Network().ListenAddresses() {
+			status.ListenAddrs = append(status.ListenAddrs, addr.String())
+		}
+
+		for _, addr := range h.Addrs() {
+			status.Multiaddrs = append(status.Multiaddrs, fmt.Sprintf("%s/p2p/%s", addr, h.ID()))
+		}
+
+		for _, remote := range peerManager.List() {
+			status.ConnectedPeers = append(status.ConnectedPeers, remote.ID.String())
+		}
+
+		status.Supervised = peerManager.Supervised()
+		status.Events = peerManager.Events()
+	}
+
+	c.JSON(http.StatusOK, status)
+}
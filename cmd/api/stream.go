@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/jhonjoao/remote-containers/internal/peers"
+)
+
+// remoteStream is a request answered by the other node with stream chunks.
+type remoteStream struct {
+	id       string
+	peer     *peers.Peer
+	messages <-chan communication.Envelope
+	finished bool
+}
+
+// openStream sends the gin request to the other node as a streaming
+// request and returns the messages that answer it, or the status code to
+// answer with when it can't be sent.
+func openStream(c *gin.Context) (*remoteStream, int, error) {
+
+	remote, status, err := targetPeer(c)
+	if err != nil {
+		return nil, status, err
+	}
+
+	id := uuid.New().String()
+
+	requestData, err := ginContextToBytes(c, id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	messages := remote.Pending.RegisterStream(id)
+
+	err = remote.Send(communication.Envelope{
+		Id:      id,
+		Kind:    communication.KindRequest,
+		Payload: requestData,
+	})
+	if err != nil {
+		remote.Pending.Remove(id)
+		return nil, http.StatusServiceUnavailable, fmt.Errorf("error sending request to another node: %w", err)
+	}
+
+	return &remoteStream{id: id, peer: remote, messages: messages}, http.StatusOK, nil
+}
+
+// close stops waiting for the stream and, when it did not end on its own,
+// tells the other node to stop producing it.
+func (s *remoteStream) close() {
+	s.peer.Pending.Remove(s.id)
+
+	if s.finished {
+		return
+	}
+
+	err := s.peer.Send(communication.Envelope{
+		Id:   s.id,
+		Kind: communication.KindCancel,
+	})
+	if err != nil {
+		logger.Errorf("Error cancelling remote stream: %s", err)
+	}
+}
+
+// relay copies the stream chunks to the HTTP response through write, which
+// returns false when the client can't take more data. Errors received
+// before the first chunk are answered with their own status code, those
+// received after it are returned as the response is already under way.
+func (s *remoteStream) relay(c *gin.Context, contentType string, write func(w io.Writer, chunk []byte) bool) error {
+
+	started := false
+
+	for {
+		var message communication.Envelope
+
+		select {
+		case message = <-s.messages:
+		case <-c.Request.Context().Done():
+			return nil
+		}
+
+		switch message.Kind {
+		case communication.KindStreamChunk:
+			if !started {
+				c.Header("Content-Type", contentType)
+				c.Status(http.StatusOK)
+				started = true
+			}
+
+			if !write(c.Writer, message.Payload) {
+				return nil
+			}
+			c.Writer.Flush()
+
+		case communication.KindError:
+			s.finished = true
+			if !started {
+				status := message.Status
+				if status < http.StatusBadRequest {
+					status = http.StatusInternalServerError
+				}
+				c.JSON(status, gin.H{"error": message.Error})
+				return nil
+			}
+			return errors.New(message.Error)
+
+		default:
+			s.finished = true
+			if !started {
+				c.Header("Content-Type", contentType)
+				c.Status(http.StatusOK)
+			}
+			return nil
+		}
+	}
+}
+
+// abort closes the connection of a response that failed after it started,
+// so the client can't mistake what it got for the whole response.
+func abort(c *gin.Context) {
+	c.Writer.Flush()
+
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+}
+
+// wantsEventStream reports whether the client asked for Server-Sent Events.
+func wantsEventStream(c *gin.Context) bool {
+	return c.NegotiateFormat("text/event-stream", gin.MIMEPlain) == "text/event-stream"
+}
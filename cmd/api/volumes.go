@@ -0,0 +1,63 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary creates a Docker volume
+// @Accept json
+// @Produce json
+// @Param data body docker.VolumeCreateRequest false "volume options"
+// @Success 201	{object} map[string]interface{}  "created"
+// @Failure 400	{object} map[string]interface{}  "invalid request"
+// @Router /volumes/create [post]
+func createVolume(c *gin.Context) {
+	relayResponse(c, "result")
+}
+
+// @Summary lists Docker volumes
+// @Accept  */*
+// @Produce  json
+// @Param filter query []string false "key=value filters, e.g. dangling=true or label=env=dev" collectionFormat(multi)
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 400	{object} map[string]interface{}  "invalid filter"
+// @Router /volumes/list [get]
+func listVolumes(c *gin.Context) {
+	relayResponse(c, "volumes")
+}
+
+// @Summary inspects a Docker volume
+// @Accept  */*
+// @Produce  json
+// @Param name path string true "volume name"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 404	{object} map[string]interface{}  "volume not found"
+// @Router /volumes/:name [get]
+func inspectVolume(c *gin.Context) {
+	relayResponse(c, "result")
+}
+
+// @Summary removes a Docker volume
+// @Accept  */*
+// @Produce  json
+// @Param name path string true "volume name"
+// @Param force query bool false "remove the volume even if it is in use"
+// @Success 200	{object} map[string]interface{}  "ok"
+// @Failure 404	{object} map[string]interface{}  "volume not found"
+// @Failure 409	{object} map[string]interface{}  "volume is in use"
+// @Router /volumes/:name [delete]
+func removeVolume(c *gin.Context) {
+	relayResponse(c, "message")
+}
+
+// @Summary removes unused Docker volumes
+// @Accept  */*
+// @Produce  json
+// @Param all query bool false "also remove unused named volumes"
+// @Param filter query []string false "key=value filters, e.g. label=env=dev" collectionFormat(multi)
+// @Success 200	{object} map[string]interface{}  "deleted volumes and reclaimed space"
+// @Failure 400	{object} map[string]interface{}  "invalid filter"
+// @Router /volumes/prune [post]
+func pruneVolumes(c *gin.Context) {
+	relayResponse(c, "result")
+}
@@ -0,0 +1,81 @@
+package internalapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+	"github.com/jhonjoao/remote-containers/internal/trust"
+	"github.com/libp2p/go-libp2p/core/network"
+)
+
+// ArchiveStreamHandler serves the archive downloads and uploads the other
+// node opens on communication.ArchiveProtocol streams. Peers with the
+// viewer role can only download.
+func ArchiveStreamHandler(client docker.DockerClient) func(s network.Stream, role string) {
+	return func(s network.Stream, role string) {
+		defer s.Close()
+
+		writer := communication.NewFrameWriter(s)
+
+		end := runArchive(client, bufio.NewReader(s), writer, role)
+		if end.Error != "" {
+			logger.Errorf("Archive transfer failed: %s", end.Error)
+		}
+
+		err := writer.WriteJSON(communication.FrameArchiveEnd, end)
+		if err != nil {
+			logger.Errorf("Error ending archive stream: %s", err)
+		}
+	}
+}
+
+func runArchive(client docker.DockerClient, reader *bufio.Reader, writer *communication.FrameWriter, role string) communication.ArchiveEnd {
+
+	frame, err := communication.ReadFrame(reader)
+	if err != nil {
+		return archiveError(http.StatusBadRequest, fmt.Errorf("failed to read archive request: %w", err))
+	}
+
+	if frame.Type != communication.FrameArchiveStart {
+		return archiveError(http.StatusBadRequest, fmt.Errorf("expected archive start frame, got frame type %d", frame.Type))
+	}
+
+	var start communication.ArchiveStart
+	err = json.Unmarshal(frame.Payload, &start)
+	if err != nil {
+		return archiveError(http.StatusBadRequest, fmt.Errorf("invalid archive request: %w", err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	switch {
+	case !start.Upload:
+		// Writes to a stream the other node reset fail, which stops the
+		// copy.
+		err = client.CopyFromContainer(ctx, start.ContainerID, start.Path, writer.Output(communication.FrameData))
+	case role == trust.RoleViewer:
+		return archiveError(http.StatusForbidden, errViewer)
+	default:
+		// The rest of the stream is the archive. A stream reset before it
+		// ends fails the upload instead of extracting part of it.
+		err = client.CopyToContainer(ctx, start.ContainerID, start.Path, reader, docker.CopyToContainerOptions{
+			NoOverwriteDirNonDir: start.NoOverwriteDirNonDir,
+			CopyUIDGID:           start.CopyUIDGID,
+		})
+	}
+	if err != nil {
+		return archiveError(docker.StatusCode(err), err)
+	}
+
+	return communication.ArchiveEnd{Status: http.StatusOK}
+}
+
+func archiveError(status int, err error) communication.ArchiveEnd {
+	return communication.ArchiveEnd{Status: status, Error: err.Error()}
+}
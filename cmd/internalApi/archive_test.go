@@ -0,0 +1,152 @@
+package internalapi
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhonjoao/remote-containers/cmd/api"
+	"github.com/jhonjoao/remote-containers/internal/peers"
+)
+
+// startAPI serves the HTTP API of the node manager belongs to and returns
+// its base URL. The API keeps its state in package variables, so a test
+// starts it once.
+func startAPI(t *testing.T, manager *peers.Manager) string {
+	t.Helper()
+
+	listener, err := api.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go api.StartApi(manager, nil, listener)
+
+	return "http://" + listener.Addr().String()
+}
+
+// archive returns size random bytes. The content of an archive is never
+// looked at on the way, so it does not need to be a real tar.
+func archive(t *testing.T, size int) []byte {
+	t.Helper()
+
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestArchive(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	// Larger than the transport window, so both directions have to wait
+	// for the other end to read.
+	data := archive(t, 4<<20)
+
+	engine := &fakeDocker{
+		running:  map[string]bool{"web": true},
+		archives: map[string][]byte{"/data": data, brokenArchive: data},
+	}
+	manager, _ := connectNodes(t, engine)
+	base := startAPI(t, manager)
+
+	uploads := []struct {
+		name   string
+		uri    string
+		status int
+	}{
+		{"upload", "/containers/web/archive?path=/upload&copyUIDGID=true", http.StatusOK},
+		{"upload to missing container", "/containers/missing/archive?path=/upload", http.StatusNotFound},
+		{"upload without path", "/containers/web/archive", http.StatusBadRequest},
+		{"upload with invalid option", "/containers/web/archive?path=/upload&noOverwriteDirNonDir=maybe", http.StatusBadRequest},
+	}
+
+	for _, test := range uploads {
+		t.Run(test.name, func(t *testing.T) {
+
+			request, err := http.NewRequest(http.MethodPut, base+test.uri, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.Header.Set("Content-Type", "application/x-tar")
+
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(response.Body)
+			response.Body.Close()
+
+			if response.StatusCode != test.status {
+				t.Fatalf("got status %d (%s), want %d", response.StatusCode, body, test.status)
+			}
+		})
+	}
+
+	t.Run("uploaded archive", func(t *testing.T) {
+
+		engine.mu.Lock()
+		defer engine.mu.Unlock()
+
+		if !bytes.Equal(engine.archives["/upload"], data) {
+			t.Fatalf("the daemon got %d bytes, want the %d bytes uploaded", len(engine.archives["/upload"]), len(data))
+		}
+	})
+
+	t.Run("download", func(t *testing.T) {
+
+		response, err := http.Get(base + "/containers/web/archive?path=/data")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "application/x-tar" {
+			t.Fatalf("got status %d with content type %q", response.StatusCode, response.Header.Get("Content-Type"))
+		}
+		if !bytes.Equal(body, data) {
+			t.Fatalf("got %d bytes, want the %d bytes of the archive", len(body), len(data))
+		}
+	})
+
+	t.Run("download of missing path", func(t *testing.T) {
+
+		response, err := http.Get(base + "/containers/web/archive?path=/missing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		if response.StatusCode != http.StatusNotFound {
+			t.Fatalf("got status %d, want %d", response.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	// The daemon fails after the archive started, which has to reach the
+	// client as a broken response rather than a shorter archive.
+	t.Run("download failing partway", func(t *testing.T) {
+
+		response, err := http.Get(base + "/containers/web/archive?path=" + brokenArchive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		if err == nil {
+			t.Fatalf("got a complete response of %d bytes with status %d", len(body), response.StatusCode)
+		}
+	})
+}
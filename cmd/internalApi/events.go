@@ -0,0 +1,56 @@
+package internalapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/jhonjoao/remote-containers/cmd/api"
+	"github.com/jhonjoao/remote-containers/internal/communication"
+)
+
+// eventFilterShortcuts are query parameters accepted as shorthands for
+// filter=key=value.
+var eventFilterShortcuts = []string{"type", "event", "container", "image", "label", "network", "volume"}
+
+func dockerEvents(w *api.TransactionRequest) {
+
+	query := queryValues(w)
+
+	eventFilters, err := queryFilters(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	for _, key := range eventFilterShortcuts {
+		for _, value := range query[key] {
+			eventFilters.Add(key, value)
+		}
+	}
+
+	err = dockerClient.WatchEvents(w.Ctx, eventFilters, query.Get("since"), func(message events.Message) error {
+		payload, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+
+		return w.Peer.Send(communication.Envelope{
+			Id:      w.Id,
+			Kind:    communication.KindEvent,
+			Payload: payload,
+		})
+	})
+	if err != nil {
+		if w.Ctx.Err() == nil {
+			respondDockerError(w, err)
+		}
+		return
+	}
+
+	if w.Ctx.Err() != nil {
+		return
+	}
+
+	respondEnd(w)
+}
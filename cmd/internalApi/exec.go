@@ -0,0 +1,137 @@
+package internalapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+	"github.com/jhonjoao/remote-containers/internal/trust"
+	"github.com/libp2p/go-libp2p/core/network"
+)
+
+// ExecStreamHandler runs the interactive exec sessions the other node opens
+// on communication.ExecProtocol streams. Peers with the viewer role can't
+// open them.
+func ExecStreamHandler(client docker.DockerClient) func(s network.Stream, role string) {
+	return func(s network.Stream, role string) {
+		defer s.Close()
+
+		writer := communication.NewFrameWriter(s)
+
+		err := errViewer
+		if role != trust.RoleViewer {
+			err = runExec(client, bufio.NewReader(s), writer)
+		}
+		if err != nil {
+			logger.Errorf("Exec session failed: %s", err)
+			writer.WriteJSON(communication.FrameExit, communication.ExecExit{
+				ExitCode: -1,
+				Error:    err.Error(),
+			})
+		}
+	}
+}
+
+func runExec(client docker.DockerClient, reader *bufio.Reader, writer *communication.FrameWriter) error {
+
+	frame, err := communication.ReadFrame(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read exec request: %w", err)
+	}
+
+	if frame.Type != communication.FrameExecStart {
+		return fmt.Errorf("expected exec start frame, got frame type %d", frame.Type)
+	}
+
+	var start communication.ExecStart
+	err = json.Unmarshal(frame.Payload, &start)
+	if err != nil {
+		return fmt.Errorf("invalid exec request: %w", err)
+	}
+
+	config := types.ExecConfig{
+		Cmd:        start.Cmd,
+		Tty:        start.Tty,
+		Env:        start.Env,
+		User:       start.User,
+		WorkingDir: start.WorkingDir,
+	}
+
+	if start.Tty && start.Height > 0 && start.Width > 0 {
+		config.ConsoleSize = &[2]uint{start.Height, start.Width}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	execID, attach, err := client.StartExec(ctx, start.ContainerID, config)
+	if err != nil {
+		return err
+	}
+	defer attach.Close()
+
+	go forwardExecInput(ctx, client, reader, attach, execID)
+
+	if start.Tty {
+		_, err = io.Copy(writer.Output(communication.FrameStdout), attach.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(writer.Output(communication.FrameStdout), writer.Output(communication.FrameStderr), attach.Reader)
+	}
+	if err != nil {
+		logger.Warnf("Exec output ended with error: %s", err)
+	}
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, 10*time.Second)
+	defer cancelWait()
+
+	exitCode, err := client.ExecExitCode(waitCtx, execID)
+	if err != nil {
+		return err
+	}
+
+	return writer.WriteJSON(communication.FrameExit, communication.ExecExit{ExitCode: exitCode})
+}
+
+// forwardExecInput applies the stdin and resize frames sent by the other
+// node until its side of the stream is closed, which also detaches from
+// the exec process.
+func forwardExecInput(ctx context.Context, client docker.DockerClient, reader *bufio.Reader, attach types.HijackedResponse, execID string) {
+	defer attach.Close()
+
+	for {
+		frame, err := communication.ReadFrame(reader)
+		if err != nil {
+			return
+		}
+
+		switch frame.Type {
+		case communication.FrameStdin:
+			_, err = attach.Conn.Write(frame.Payload)
+			if err != nil {
+				return
+			}
+
+		case communication.FrameStdinClose:
+			attach.CloseWrite()
+
+		case communication.FrameResize:
+			var size communication.ExecResize
+			if json.Unmarshal(frame.Payload, &size) == nil {
+				err = client.ResizeExec(ctx, execID, size.Height, size.Width)
+				if err != nil {
+					logger.Warnf("Error resizing exec: %s", err)
+				}
+			}
+
+		default:
+			logger.Warnf("Ignoring exec frame of unexpected type %d", frame.Type)
+		}
+	}
+}
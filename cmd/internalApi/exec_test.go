@@ -0,0 +1,205 @@
+package internalapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/jhonjoao/remote-containers/internal/trust"
+)
+
+// execExitCode is the exit code of every fake exec process.
+const execExitCode = 3
+
+// fakeExec is an exec instance of fakeDocker. Its process echoes stdin
+// back until stdin is closed.
+type fakeExec struct {
+	running bool
+	resizes []string
+}
+
+func (f *fakeDocker) createExec(w http.ResponseWriter, containerID string) {
+
+	if f.execs == nil {
+		f.execs = map[string]*fakeExec{}
+	}
+
+	id := fmt.Sprintf("%s-exec-%d", containerID, len(f.execs))
+	f.execs[id] = &fakeExec{}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"Id": id})
+}
+
+func (f *fakeDocker) exec(w http.ResponseWriter, r *http.Request, id, action string) {
+
+	f.mu.Lock()
+	exec, exists := f.execs[id]
+	f.mu.Unlock()
+
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "No such exec instance: " + id})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && action == "start":
+		f.startExec(w, r, exec)
+
+	case r.Method == http.MethodPost && action == "resize":
+		f.mu.Lock()
+		exec.resizes = append(exec.resizes, r.URL.Query().Get("h")+"x"+r.URL.Query().Get("w"))
+		f.mu.Unlock()
+
+	case r.Method == http.MethodGet && action == "json":
+		f.mu.Lock()
+		running := exec.running
+		f.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"ID": id, "Running": running, "ExitCode": execExitCode})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// startExec takes over the connection like the daemon does, and runs the
+// process on it.
+func (f *fakeDocker) startExec(w http.ResponseWriter, r *http.Request, exec *fakeExec) {
+
+	// The start options come before the stream.
+	io.Copy(io.Discard, r.Body)
+
+	conn, buffered, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	f.mu.Lock()
+	exec.running = true
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		exec.running = false
+		f.mu.Unlock()
+	}()
+
+	fmt.Fprint(buffered, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+	buffered.Flush()
+
+	io.Copy(conn, buffered)
+}
+
+// openExec opens an exec stream to the other node and sends start on it.
+func openExec(t *testing.T, role string, start communication.ExecStart) (*fakeDocker, *bufio.Reader, *communication.FrameWriter) {
+	t.Helper()
+
+	engine := &fakeDocker{running: map[string]bool{"web": true}}
+	manager, remote := connectNodesAs(t, engine, role)
+
+	s, err := manager.Host().NewStream(context.Background(), remote.ID, communication.ExecProtocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Reset() })
+	s.SetDeadline(time.Now().Add(5 * time.Second))
+
+	writer := communication.NewFrameWriter(s)
+	if err := writer.WriteJSON(communication.FrameExecStart, start); err != nil {
+		t.Fatal(err)
+	}
+
+	return engine, bufio.NewReader(s), writer
+}
+
+func TestExecSession(t *testing.T) {
+
+	engine, reader, writer := openExec(t, trust.RoleAdmin, communication.ExecStart{ContainerID: "web", Cmd: []string{"cat"}, Tty: true})
+
+	for _, frame := range []communication.Frame{
+		{Type: communication.FrameStdin, Payload: []byte("hello\n")},
+		{Type: communication.FrameResize, Payload: []byte(`{"height":24,"width":80}`)},
+		{Type: communication.FrameStdin, Payload: []byte("bye\n")},
+		{Type: communication.FrameStdinClose},
+	} {
+		if err := writer.WriteFrame(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var output []byte
+	var exit communication.ExecExit
+
+	for done := false; !done; {
+		frame, err := communication.ReadFrame(reader)
+		if err != nil {
+			t.Fatalf("exec stream closed before the exit frame: %s", err)
+		}
+
+		switch frame.Type {
+		case communication.FrameStdout:
+			output = append(output, frame.Payload...)
+		case communication.FrameExit:
+			if err := json.Unmarshal(frame.Payload, &exit); err != nil {
+				t.Fatal(err)
+			}
+			done = true
+		default:
+			t.Fatalf("unexpected frame type %d", frame.Type)
+		}
+	}
+
+	if string(output) != "hello\nbye\n" {
+		t.Errorf("got output %q, want the stdin echoed back", output)
+	}
+	if exit != (communication.ExecExit{ExitCode: execExitCode}) {
+		t.Errorf("got exit %+v, want exit code %d", exit, execExitCode)
+	}
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	if len(engine.execs) != 1 {
+		t.Fatalf("got %d exec instances, want 1", len(engine.execs))
+	}
+	for _, exec := range engine.execs {
+		if !reflect.DeepEqual(exec.resizes, []string{"24x80"}) {
+			t.Errorf("got resizes %v, want 24x80", exec.resizes)
+		}
+	}
+}
+
+func TestExecRefusesViewers(t *testing.T) {
+
+	engine, reader, _ := openExec(t, trust.RoleViewer, communication.ExecStart{ContainerID: "web", Cmd: []string{"sh"}})
+
+	frame, err := communication.ReadFrame(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exit communication.ExecExit
+	if frame.Type != communication.FrameExit || json.Unmarshal(frame.Payload, &exit) != nil {
+		t.Fatalf("got frame type %d, want an exit frame", frame.Type)
+	}
+	if exit.ExitCode != -1 || exit.Error != errViewer.Error() {
+		t.Fatalf("got exit %+v, want the viewer error", exit)
+	}
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	if len(engine.execs) != 0 {
+		t.Fatalf("%d exec instances were created for a viewer", len(engine.execs))
+	}
+}
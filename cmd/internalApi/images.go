@@ -0,0 +1,166 @@
+package internalapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jhonjoao/remote-containers/cmd/api"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+)
+
+func pullImage(w *api.TransactionRequest) {
+
+	var request docker.PullRequest
+
+	err := json.Unmarshal(w.Body, &request)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	err = dockerClient.PullImage(w.Ctx, request, func(progress docker.PullProgress) error {
+		line, err := json.Marshal(progress)
+		if err != nil {
+			return err
+		}
+
+		return respondChunk(w, append(line, '\n'))
+	})
+	if err != nil {
+		if w.Ctx.Err() == nil {
+			respondDockerError(w, err)
+		}
+		return
+	}
+
+	if w.Ctx.Err() != nil {
+		return
+	}
+
+	respondEnd(w)
+}
+
+func listImages(w *api.TransactionRequest) {
+
+	query := queryValues(w)
+
+	all, err := queryBool(query, "all")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	imageFilters, err := queryFilters(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	images, err := dockerClient.ListImages(types.ImageListOptions{
+		All:     all,
+		Filters: imageFilters,
+	})
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, images)
+}
+
+func inspectImage(w *api.TransactionRequest) {
+
+	imageId, _ := w.Params.Get("id")
+
+	response, err := dockerClient.InspectImage(imageId)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, response)
+}
+
+func removeImage(w *api.TransactionRequest) {
+
+	imageId, _ := w.Params.Get("id")
+
+	query := queryValues(w)
+
+	options := types.ImageRemoveOptions{}
+
+	var err error
+	options.Force, err = queryBool(query, "force")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	noPrune, err := queryBool(query, "noprune")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	options.PruneChildren = !noPrune
+
+	response, err := dockerClient.RemoveImage(imageId, options)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, response)
+}
+
+func tagImage(w *api.TransactionRequest) {
+
+	imageId, _ := w.Params.Get("id")
+
+	query := queryValues(w)
+
+	target := query.Get("repo")
+	if target == "" {
+		respondError(w, http.StatusBadRequest, errors.New("query parameter repo is required"))
+		return
+	}
+
+	if tag := query.Get("tag"); tag != "" {
+		target += ":" + tag
+	}
+
+	err := dockerClient.TagImage(imageId, target)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusCreated, target)
+}
+
+func pruneImages(w *api.TransactionRequest) {
+
+	query := queryValues(w)
+
+	all, err := queryBool(query, "all")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pruneFilters, err := queryFilters(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := dockerClient.PruneImages(all, pruneFilters)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, report)
+}
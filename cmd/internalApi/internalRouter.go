@@ -1,18 +1,29 @@
 package internalapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
-	"time"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	logging "github.com/ipfs/go-log/v2"
 	"github.com/jhonjoao/remote-containers/cmd/api"
 	"github.com/jhonjoao/remote-containers/internal/communication"
 	"github.com/jhonjoao/remote-containers/internal/docker"
-	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/jhonjoao/remote-containers/internal/peers"
+	"github.com/jhonjoao/remote-containers/internal/trust"
 )
 
+var logger = logging.Logger("remote-containers/internal-api")
+
 type InternalHandler func(*api.TransactionRequest)
 
 type InternalRouter struct {
@@ -21,83 +32,401 @@ type InternalRouter struct {
 	Handler InternalHandler `json:"Handler"`
 }
 
-var stream network.Stream
 var dockerClient docker.DockerClient
 
-func ProcessInternalData(s network.Stream, client docker.DockerClient, channel chan communication.ResponseData, apiChan chan communication.ResponseData) {
+// errViewer answers the requests that would change something on this node
+// when they come from a peer with the viewer role.
+var errViewer = errors.New("peer has the viewer role and can only read")
+
+// requestKey identifies a request among those of every connection, as each
+// peer picks the ids of its own requests.
+type requestKey struct {
+	peer *peers.Peer
+	id   string
+}
+
+// inflight holds the cancel function of every request being handled, so a
+// cancel message from the other node can stop e.g. a log follower.
+var inflight = struct {
+	sync.Mutex
+	cancels map[requestKey]context.CancelFunc
+}{cancels: map[requestKey]context.CancelFunc{}}
+
+// MessageHandler returns the peers.Handler that serves the requests of
+// every peer with client and hands their responses to the requests waiting
+// for them.
+func MessageHandler(client docker.DockerClient) peers.Handler {
 
-	stream = s
 	dockerClient = client
 
+	return ProcessInternalData
+}
+
+func ProcessInternalData(remote *peers.Peer, channel <-chan communication.Envelope) {
+
 	for {
 		value, ok := <-channel
 		if !ok {
-			fmt.Println("Channel closed. Exiting goroutine.")
+			logger.Debugf("Messages of peer %s ended", remote.ID)
+			cancelPeerRequests(remote)
 			return
 		}
 
-		var data api.TransactionRequest
-		json.Unmarshal(value.Data, &data)
+		switch value.Kind {
+		case communication.KindRequest:
+			handleRequest(remote, value)
+		case communication.KindCancel:
+			cancelRequest(requestKey{remote, value.Id})
+		case communication.KindResponse, communication.KindError, communication.KindEvent, communication.KindStreamChunk:
+			resolve(remote, value)
+		default:
+			logger.Warnf("Ignoring message of unsupported kind %s", value.Kind)
+		}
+	}
+
+}
 
-		if data.Method == "" {
-			select {
-			case apiChan <- value:
-			case <-time.After(10 * time.Second):
-				fmt.Println("Failed to add response in channel")
-			}
-			continue
+// resolve hands a message answering one of our requests to its waiter. A
+// stream whose HTTP client is too slow to keep up is cancelled on the
+// other node instead of blocking the messages of every other request.
+func resolve(remote *peers.Peer, value communication.Envelope) {
+
+	err := remote.Pending.Resolve(value)
+	switch {
+	case errors.Is(err, communication.ErrReaderTooSlow):
+		logger.Warnf("Cancelling stream of request %s: %s", value.Id, err)
+		err = remote.Send(communication.Envelope{Id: value.Id, Kind: communication.KindCancel})
+		if err != nil {
+			logger.Errorf("Error cancelling remote stream: %s", err)
 		}
+	case err != nil:
+		logger.Debugf("Discarding %s for unknown request %s", value.Kind, value.Id)
+	}
+}
+
+func handleRequest(remote *peers.Peer, value communication.Envelope) {
+
+	var data api.TransactionRequest
+	err := json.Unmarshal(value.Payload, &data)
+	if err != nil {
+		data.Id = value.Id
+		data.Peer = remote
+		respondError(&data, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+
+	data.Id = value.Id
+	data.Peer = remote
+
+	// The escaped path keeps an encoded "/" inside a parameter, such as an
+	// image reference, from being taken as a path separator.
+	path := data.Uri
+	if uri, err := url.ParseRequestURI(data.Uri); err == nil {
+		path = uri.EscapedPath()
+	}
+
+	if remote.Role == trust.RoleViewer && data.Method != http.MethodGet {
+		respondError(&data, http.StatusForbidden, errViewer)
+		return
+	}
+
+	for _, route := range internalRoutes()[data.Method] {
 
-		if value.Err != nil {
-			fmt.Println("Error to listen data", value.Err.Error())
+		if (data.Params != nil && matchRoute(route.Path, path)) || route.Path == path {
+			// The cancel function is registered before the handler starts,
+			// for a cancel message read right after the request to find it.
+			ctx, cancel := context.WithCancel(context.Background())
+			data.Ctx = ctx
+			inflight.Lock()
+			inflight.cancels[requestKey{remote, data.Id}] = cancel
+			inflight.Unlock()
+
+			go runHandler(route.Handler, &data, cancel)
 			return
 		}
+	}
 
-		routes := map[string][]InternalRouter{}
+	respondError(&data, http.StatusNotFound, fmt.Errorf("no route for %s %s", data.Method, path))
+}
 
-		routes["GET"] = make([]InternalRouter, 0)
+func runHandler(handler InternalHandler, w *api.TransactionRequest, cancel context.CancelFunc) {
 
-		routes["GET"] = append(routes["GET"], InternalRouter{
-			Path:    "/containers/list",
-			Handler: listContainers,
-		})
+	key := requestKey{w.Peer, w.Id}
 
-		routes["GET"] = append(routes["GET"], InternalRouter{
-			Path:    "/containers/:id",
-			Handler: inspectContainer,
-		})
+	defer func() {
+		inflight.Lock()
+		delete(inflight.cancels, key)
+		inflight.Unlock()
+		cancel()
+	}()
 
-		routes["POST"] = make([]InternalRouter, 0)
+	handler(w)
+}
 
-		routes["POST"] = append(routes["POST"], InternalRouter{
-			Path:    "/containers/create",
-			Handler: createContainer,
-		})
+func cancelRequest(key requestKey) {
+	inflight.Lock()
+	cancel, ok := inflight.cancels[key]
+	inflight.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
 
-		routes["DELETE"] = make([]InternalRouter, 0)
+// cancelPeerRequests stops every request of a peer that went away, as
+// nobody is left to read their responses.
+func cancelPeerRequests(remote *peers.Peer) {
+	inflight.Lock()
+	for key, cancel := range inflight.cancels {
+		if key.peer == remote {
+			cancel()
+		}
+	}
+	inflight.Unlock()
+}
 
+func internalRoutes() map[string][]InternalRouter {
+
+	routes := map[string][]InternalRouter{}
+
+	routes["GET"] = make([]InternalRouter, 0)
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/containers/list",
+		Handler: listContainers,
+	})
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/containers/:id",
+		Handler: inspectContainer,
+	})
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/containers/:id/logs",
+		Handler: containerLogs,
+	})
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/containers/:id/stats",
+		Handler: containerStats,
+	})
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/stats",
+		Handler: allStats,
+	})
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/events",
+		Handler: dockerEvents,
+	})
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/images/list",
+		Handler: listImages,
+	})
+
+	routes["GET"] = append(routes["GET"], InternalRouter{
+		Path:    "/images/:id",
+		Handler: inspectImage,
+	})
+
+	routes["GET"] = append(routes["GET"],
+		InternalRouter{Path: "/volumes/list", Handler: listVolumes},
+		InternalRouter{Path: "/volumes/:name", Handler: inspectVolume},
+		InternalRouter{Path: "/networks/list", Handler: listNetworks},
+		InternalRouter{Path: "/networks/:id", Handler: inspectNetwork},
+	)
+
+	routes["POST"] = make([]InternalRouter, 0)
+
+	routes["POST"] = append(routes["POST"], InternalRouter{
+		Path:    "/containers/create",
+		Handler: createContainer,
+	})
+
+	for action, handler := range map[string]InternalHandler{
+		"start":   startContainer,
+		"stop":    stopContainer,
+		"restart": restartContainer,
+		"kill":    killContainer,
+		"pause":   pauseContainer,
+		"unpause": unpauseContainer,
+	} {
 		routes["POST"] = append(routes["POST"], InternalRouter{
-			Path:    "/containers/:id",
-			Handler: deleteContainer,
+			Path:    "/containers/:id/" + action,
+			Handler: handler,
 		})
+	}
+
+	routes["POST"] = append(routes["POST"], InternalRouter{
+		Path:    "/images/pull",
+		Handler: pullImage,
+	})
+
+	routes["POST"] = append(routes["POST"], InternalRouter{
+		Path:    "/images/prune",
+		Handler: pruneImages,
+	})
+
+	routes["POST"] = append(routes["POST"], InternalRouter{
+		Path:    "/images/:id/tag",
+		Handler: tagImage,
+	})
+
+	routes["POST"] = append(routes["POST"],
+		InternalRouter{Path: "/volumes/create", Handler: createVolume},
+		InternalRouter{Path: "/volumes/prune", Handler: pruneVolumes},
+		InternalRouter{Path: "/networks/create", Handler: createNetwork},
+		InternalRouter{Path: "/networks/prune", Handler: pruneNetworks},
+		InternalRouter{Path: "/networks/:id/connect", Handler: connectNetwork},
+		InternalRouter{Path: "/networks/:id/disconnect", Handler: disconnectNetwork},
+	)
+
+	routes["DELETE"] = make([]InternalRouter, 0)
+
+	routes["DELETE"] = append(routes["DELETE"], InternalRouter{
+		Path:    "/containers/:id",
+		Handler: deleteContainer,
+	})
+
+	routes["DELETE"] = append(routes["DELETE"], InternalRouter{
+		Path:    "/images/:id",
+		Handler: removeImage,
+	})
+
+	routes["DELETE"] = append(routes["DELETE"],
+		InternalRouter{Path: "/volumes/:name", Handler: removeVolume},
+		InternalRouter{Path: "/networks/:id", Handler: removeNetwork},
+	)
+
+	return routes
+}
+
+// respond sends a successful response for the request w back to the node
+// that made it.
+func respond(w *api.TransactionRequest, status int, value interface{}) {
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Errorf("failed to marshal response: %w", err))
+		return
+	}
+
+	err = w.Peer.Send(communication.Envelope{
+		Id:      w.Id,
+		Kind:    communication.KindResponse,
+		Status:  status,
+		Payload: bytes,
+	})
+	if err != nil {
+		logger.Errorf("Error sending response: %s", err)
+	}
+}
 
-		for _, routes := range routes[data.Method] {
+// respondChunk sends part of a streaming response. The payload is sent as
+// is, split in chunks of at most communication.StreamChunkSize bytes.
+func respondChunk(w *api.TransactionRequest, data []byte) error {
 
-			if data.Params != nil {
-				if matchRoute(routes.Path, data.Uri) {
-					routes.Handler(&data)
-					break
-				}
-			}
+	for len(data) > 0 {
+		size := min(len(data), communication.StreamChunkSize)
 
-			if routes.Path == data.Uri {
-				routes.Handler(&data)
-				break
-			}
+		err := w.Peer.Send(communication.Envelope{
+			Id:      w.Id,
+			Kind:    communication.KindStreamChunk,
+			Payload: data[:size],
+		})
+		if err != nil {
+			return err
 		}
 
+		data = data[size:]
+	}
+
+	return nil
+}
+
+// chunkWriter adapts respondChunk to io.Writer.
+type chunkWriter struct {
+	w *api.TransactionRequest
+}
+
+func (c chunkWriter) Write(p []byte) (int, error) {
+	if err := respondChunk(c.w, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// respondEnd closes a streaming response.
+func respondEnd(w *api.TransactionRequest) {
+
+	err := w.Peer.Send(communication.Envelope{
+		Id:     w.Id,
+		Kind:   communication.KindResponse,
+		Status: http.StatusOK,
+	})
+	if err != nil {
+		logger.Errorf("Error sending response: %s", err)
+	}
+}
+
+func respondError(w *api.TransactionRequest, status int, cause error) {
+
+	err := w.Peer.Send(communication.Envelope{
+		Id:     w.Id,
+		Kind:   communication.KindError,
+		Status: status,
+		Error:  cause.Error(),
+	})
+	if err != nil {
+		logger.Errorf("Error sending response: %s", err)
+	}
+}
+
+// respondDockerError answers with the status code matching the class of the
+// Docker error, so e.g. a missing container is a 404 for the caller.
+func respondDockerError(w *api.TransactionRequest, err error) {
+	respondError(w, docker.StatusCode(err), err)
+}
+
+func queryValues(w *api.TransactionRequest) url.Values {
+	uri, err := url.ParseRequestURI(w.Uri)
+	if err != nil {
+		return url.Values{}
+	}
+
+	return uri.Query()
+}
+
+func queryBool(query url.Values, name string) (bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for query parameter %s", value, name)
 	}
 
+	return parsed, nil
+}
+
+// queryFilters turns the repeated filter=key=value query parameters into
+// Docker filters.
+func queryFilters(query url.Values) (filters.Args, error) {
+	args := filters.NewArgs()
+
+	for _, filter := range query["filter"] {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok || key == "" {
+			return args, fmt.Errorf("invalid filter %q, expected key=value", filter)
+		}
+		args.Add(key, value)
+	}
+
+	return args, nil
 }
 
 func matchRoute(pattern, route string) bool {
@@ -116,60 +445,72 @@ func replacePlaceholders(pattern string) string {
 
 func listContainers(w *api.TransactionRequest) {
 
-	containers := dockerClient.ListContainers(container.ListOptions{})
-
-	bytes, _ := json.Marshal(containers)
-
-	err := communication.WriteData(stream, bytes)
+	containers, err := dockerClient.ListContainers(container.ListOptions{})
 	if err != nil {
-		fmt.Println("Error sending request:", err)
+		respondDockerError(w, err)
 		return
 	}
 
+	respond(w, http.StatusOK, containers)
 }
 
 func createContainer(w *api.TransactionRequest) {
 
 	var request docker.CreateRequest
 
-	json.Unmarshal(w.Body, &request)
-
-	response := dockerClient.CreateContainer(request)
-
-	bytes, _ := json.Marshal(response)
+	err := json.Unmarshal(w.Body, &request)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
 
-	err := communication.WriteData(stream, bytes)
+	response, err := dockerClient.CreateContainer(request)
 	if err != nil {
-		fmt.Println("Error sending request:", err)
+		respondDockerError(w, err)
 		return
 	}
+
+	respond(w, http.StatusCreated, response)
 }
 
 func inspectContainer(w *api.TransactionRequest) {
 
 	containerId, _ := w.Params.Get("id")
 
-	response := dockerClient.InspectContainer(containerId)
-
-	bytes, _ := json.Marshal(response)
-
-	err := communication.WriteData(stream, bytes)
+	response, err := dockerClient.InspectContainer(containerId)
 	if err != nil {
-		fmt.Println("Error sending request:", err)
+		respondDockerError(w, err)
 		return
 	}
+
+	respond(w, http.StatusOK, response)
 }
 
 func deleteContainer(w *api.TransactionRequest) {
 	containerId, _ := w.Params.Get("id")
 
-	dockerClient.InspectContainer(containerId)
+	query := queryValues(w)
+
+	options := container.RemoveOptions{}
 
-	bytes, _ := json.Marshal("Ok")
+	var err error
+	for name, option := range map[string]*bool{
+		"force":         &options.Force,
+		"removeVolumes": &options.RemoveVolumes,
+		"removeLinks":   &options.RemoveLinks,
+	} {
+		*option, err = queryBool(query, name)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
 
-	err := communication.WriteData(stream, bytes)
+	err = dockerClient.DeleteContainer(containerId, options)
 	if err != nil {
-		fmt.Println("Error sending request:", err)
+		respondDockerError(w, err)
 		return
 	}
+
+	respond(w, http.StatusOK, "Ok")
 }
@@ -0,0 +1,355 @@
+package internalapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jhonjoao/remote-containers/cmd/api"
+	"github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+	"github.com/jhonjoao/remote-containers/internal/peers"
+	"github.com/jhonjoao/remote-containers/internal/trust"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+// brokenArchive is a path whose archive fakeDocker drops halfway through.
+const brokenArchive = "/broken"
+
+// fakeDocker serves the few Docker Engine API routes used to manage,
+// inspect and remove containers, to copy files and to exec commands,
+// keeping the state of each container in memory.
+type fakeDocker struct {
+	mu      sync.Mutex
+	running map[string]bool
+	paused  map[string]bool
+	// archives holds the tar archive of each path, shared by all containers.
+	archives map[string][]byte
+	// execs holds the exec instances created so far, by ID.
+	execs map[string]*fakeExec
+	// actions holds the query of the last lifecycle request of each kind.
+	actions map[string]url.Values
+	// followers counts the log followers that are still streaming.
+	followers int
+}
+
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	// Paths look like /v1.44/containers/<id> or /v1.44/containers/<id>/json.
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) == 4 && parts[1] == "exec" {
+		f.exec(w, r, parts[2], parts[3])
+		return
+	}
+	if len(parts) < 3 || parts[1] != "containers" {
+		http.NotFound(w, r)
+		return
+	}
+	id := parts[2]
+
+	// Followed logs never end on their own, only when the client goes away.
+	if len(parts) == 4 && parts[3] == "logs" && r.URL.Query().Get("follow") == "1" {
+		f.follow(w, r)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	running, exists := f.running[id]
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "No such container: " + id})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[3] == "json":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Id":    id,
+			"State": map[string]interface{}{"Running": running, "Paused": f.paused[id]},
+		})
+
+	case r.Method == http.MethodDelete && len(parts) == 3:
+		if running && r.URL.Query().Get("force") != "1" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{
+				"message": "cannot remove container " + id + ": container is running: stop the container before removing or force remove",
+			})
+			return
+		}
+		delete(f.running, id)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPost && len(parts) == 4 && lifecycleActions[parts[3]]:
+		f.lifecycle(w, r, id, parts[3])
+
+	case r.Method == http.MethodPost && len(parts) == 4 && parts[3] == "exec":
+		f.createExec(w, id)
+
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[3] == "archive":
+		f.download(w, r.URL.Query().Get("path"))
+
+	case r.Method == http.MethodPut && len(parts) == 4 && parts[3] == "archive":
+		archive, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.archives[r.URL.Query().Get("path")] = archive
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeDocker) download(w http.ResponseWriter, path string) {
+
+	archive, exists := f.archives[path]
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Could not find the file " + path + " in container"})
+		return
+	}
+
+	stat, _ := json.Marshal(map[string]interface{}{"name": path, "size": len(archive)})
+	w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(stat))
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.WriteHeader(http.StatusOK)
+
+	if path == brokenArchive {
+		w.Write(archive[:len(archive)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+
+	w.Write(archive)
+}
+
+func (f *fakeDocker) follow(w http.ResponseWriter, r *http.Request) {
+
+	f.mu.Lock()
+	f.followers++
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		f.followers--
+		f.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
+	<-r.Context().Done()
+}
+
+// connectNodes links two nodes in memory. The second one serves requests
+// with a Docker client talking to engine. It returns the manager of the
+// first node and the second node as seen from it.
+func connectNodes(t *testing.T, engine http.Handler) (*peers.Manager, *peers.Peer) {
+	t.Helper()
+
+	return connectNodesAs(t, engine, trust.RoleAdmin)
+}
+
+// connectNodesAs is connectNodes with the first node authorized on the
+// second one with role.
+func connectNodesAs(t *testing.T, engine http.Handler, role string) (*peers.Manager, *peers.Peer) {
+	t.Helper()
+
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+
+	dockerAPI, err := client.NewClientWithOpts(
+		client.WithHost("tcp://"+server.Listener.Addr().String()),
+		client.WithVersion("1.44"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network := mocknet.New()
+	t.Cleanup(func() { network.Close() })
+
+	local, err := network.GenPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := network.GenPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.LinkAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	admin := func(peer.ID) (string, error) { return trust.RoleAdmin, nil }
+	authorize := func(peer.ID) (string, error) { return role, nil }
+
+	dockerClient := docker.DockerClient{Client: dockerAPI}
+
+	remoteManager := peers.NewManager(remote, MessageHandler(dockerClient), authorize)
+	remote.SetStreamHandler(communication.MessageProtocol, remoteManager.HandleStream)
+	remote.SetStreamHandler(communication.ArchiveProtocol, remoteManager.Authorized(ArchiveStreamHandler(dockerClient)))
+	remote.SetStreamHandler(communication.ExecProtocol, remoteManager.Authorized(ExecStreamHandler(dockerClient)))
+
+	localManager := peers.NewManager(local, ProcessInternalData, admin)
+
+	s, err := local.NewStream(context.Background(), remote.ID(), communication.MessageProtocol)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := localManager.Add(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The other streams are only accepted once the second node added the
+	// first one.
+	deadline := time.Now().Add(5 * time.Second)
+	for len(remoteManager.List()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the second node never added the first one")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	return localManager, p
+}
+
+// send forwards a request to the other node the way the HTTP API does and
+// returns its answer.
+func send(t *testing.T, remote *peers.Peer, method, uri string, params gin.Params) communication.Envelope {
+	t.Helper()
+
+	id := uuid.New().String()
+
+	payload, err := json.Marshal(api.TransactionRequest{
+		Id:     id,
+		Method: method,
+		Uri:    uri,
+		Params: &params,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote.Pending.Register(id)
+
+	err = remote.Send(communication.Envelope{Id: id, Kind: communication.KindRequest, Payload: payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := remote.Pending.Wait(context.Background(), id, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response
+}
+
+func TestDeleteContainer(t *testing.T) {
+
+	engine := &fakeDocker{running: map[string]bool{"web": false, "db": true}}
+	_, remote := connectNodes(t, engine)
+
+	tests := []struct {
+		name   string
+		method string
+		uri    string
+		id     string
+		status int
+	}{
+		{"stopped container", http.MethodDelete, "/containers/web", "web", http.StatusOK},
+		{"container is gone", http.MethodGet, "/containers/web", "web", http.StatusNotFound},
+		{"missing container", http.MethodDelete, "/containers/missing", "missing", http.StatusNotFound},
+		{"running container", http.MethodDelete, "/containers/db", "db", http.StatusConflict},
+		{"running container is kept", http.MethodGet, "/containers/db", "db", http.StatusOK},
+		{"running container with force", http.MethodDelete, "/containers/db?force=true", "db", http.StatusOK},
+		{"forced container is gone", http.MethodGet, "/containers/db", "db", http.StatusNotFound},
+		{"invalid option", http.MethodDelete, "/containers/db?force=maybe", "db", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			response := send(t, remote, test.method, test.uri, gin.Params{{Key: "id", Value: test.id}})
+
+			status := response.Status
+			if response.Kind == communication.KindResponse && status == 0 {
+				status = http.StatusOK
+			}
+
+			if status != test.status {
+				t.Fatalf("got %s with status %d (%s), want %d", response.Kind, response.Status, response.Error, test.status)
+			}
+		})
+	}
+}
+
+func TestCancelRightAfterRequest(t *testing.T) {
+
+	engine := &fakeDocker{running: map[string]bool{"web": true}}
+	_, remote := connectNodes(t, engine)
+
+	params := gin.Params{{Key: "id", Value: "web"}}
+
+	// The cancel is sent before the other node could start the handler,
+	// which only ends once the log follower is cancelled.
+	for i := 0; i < 20; i++ {
+		id := uuid.New().String()
+
+		payload, err := json.Marshal(api.TransactionRequest{
+			Id:     id,
+			Method: http.MethodGet,
+			Uri:    "/containers/web/logs?follow=true",
+			Params: &params,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = remote.Send(communication.Envelope{Id: id, Kind: communication.KindRequest, Payload: payload})
+		if err == nil {
+			err = remote.Send(communication.Envelope{Id: id, Kind: communication.KindCancel})
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		inflight.Lock()
+		running := len(inflight.cancels)
+		inflight.Unlock()
+
+		engine.mu.Lock()
+		followers := engine.followers
+		engine.mu.Unlock()
+
+		if running == 0 && followers == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests and %d log followers still running after being cancelled", running, followers)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
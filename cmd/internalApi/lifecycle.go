@@ -0,0 +1,92 @@
+package internalapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jhonjoao/remote-containers/cmd/api"
+)
+
+func startContainer(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	respondState(w, containerId, dockerClient.StartContainer(containerId))
+}
+
+func stopContainer(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	timeout, err := queryTimeout(w)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondState(w, containerId, dockerClient.StopContainer(containerId, timeout))
+}
+
+func restartContainer(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	timeout, err := queryTimeout(w)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	respondState(w, containerId, dockerClient.RestartContainer(containerId, timeout))
+}
+
+func killContainer(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	signal := queryValues(w).Get("signal")
+
+	respondState(w, containerId, dockerClient.KillContainer(containerId, signal))
+}
+
+func pauseContainer(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	respondState(w, containerId, dockerClient.PauseContainer(containerId))
+}
+
+func unpauseContainer(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	respondState(w, containerId, dockerClient.UnpauseContainer(containerId))
+}
+
+// respondState answers a lifecycle request with the state the container is
+// left in, or with the error of the action when it failed.
+func respondState(w *api.TransactionRequest, containerId string, actionErr error) {
+	if actionErr != nil {
+		respondDockerError(w, actionErr)
+		return
+	}
+
+	state, err := dockerClient.ContainerState(containerId)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, state)
+}
+
+func queryTimeout(w *api.TransactionRequest) (*int, error) {
+	value := queryValues(w).Get("timeout")
+	if value == "" {
+		return nil, nil
+	}
+
+	// Docker waits forever on a negative timeout, which no request
+	// deadline can cover.
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout < 0 {
+		return nil, fmt.Errorf("invalid value %q for query parameter timeout, expected seconds >= 0", value)
+	}
+
+	return &timeout, nil
+}
@@ -0,0 +1,147 @@
+package internalapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jhonjoao/remote-containers/internal/communication"
+)
+
+var lifecycleActions = map[string]bool{
+	"start": true, "stop": true, "restart": true, "kill": true, "pause": true, "unpause": true,
+}
+
+// lifecycle applies the action to the container the way the daemon does,
+// including its conflicts. The caller holds f.mu.
+func (f *fakeDocker) lifecycle(w http.ResponseWriter, r *http.Request, id, action string) {
+
+	if f.paused == nil {
+		f.paused = map[string]bool{}
+	}
+	if f.actions == nil {
+		f.actions = map[string]url.Values{}
+	}
+	f.actions[action] = r.URL.Query()
+
+	conflict := func(reason string) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Container " + id + " is " + reason})
+	}
+
+	switch action {
+	case "start":
+		if f.running[id] {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		f.running[id] = true
+
+	case "stop", "kill":
+		if action == "kill" && !f.running[id] {
+			conflict("not running")
+			return
+		}
+		f.running[id] = false
+		f.paused[id] = false
+
+	case "restart":
+		f.running[id] = true
+		f.paused[id] = false
+
+	case "pause":
+		if !f.running[id] {
+			conflict("not running")
+			return
+		}
+		f.paused[id] = true
+
+	case "unpause":
+		if !f.paused[id] {
+			conflict("not paused")
+			return
+		}
+		f.paused[id] = false
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestLifecycle(t *testing.T) {
+
+	engine := &fakeDocker{running: map[string]bool{"web": true}}
+	_, remote := connectNodes(t, engine)
+
+	type state struct {
+		Running bool
+		Paused  bool
+	}
+
+	tests := []struct {
+		name   string
+		uri    string
+		status int
+		state  state
+		// query is the query the daemon got for the action, if any.
+		query map[string]string
+	}{
+		{"stop", "/containers/web/stop?timeout=5", http.StatusOK, state{}, map[string]string{"t": "5"}},
+		{"pause stopped container", "/containers/web/pause", http.StatusConflict, state{}, nil},
+		{"unpause container that is not paused", "/containers/web/unpause", http.StatusConflict, state{}, nil},
+		{"kill stopped container", "/containers/web/kill", http.StatusConflict, state{}, nil},
+		{"start", "/containers/web/start", http.StatusOK, state{Running: true}, nil},
+		{"start running container", "/containers/web/start", http.StatusOK, state{Running: true}, nil},
+		{"pause", "/containers/web/pause", http.StatusOK, state{Running: true, Paused: true}, nil},
+		{"unpause", "/containers/web/unpause", http.StatusOK, state{Running: true}, nil},
+		{"restart", "/containers/web/restart?timeout=0", http.StatusOK, state{Running: true}, map[string]string{"t": "0"}},
+		{"kill", "/containers/web/kill?signal=SIGTERM", http.StatusOK, state{}, map[string]string{"signal": "SIGTERM"}},
+		{"stop with daemon timeout", "/containers/web/stop", http.StatusOK, state{}, map[string]string{"t": ""}},
+		{"negative timeout", "/containers/web/stop?timeout=-1", http.StatusBadRequest, state{}, nil},
+		{"invalid timeout", "/containers/web/restart?timeout=soon", http.StatusBadRequest, state{}, nil},
+		{"missing container", "/containers/missing/start", http.StatusNotFound, state{}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			// The URIs look like /containers/<id>/<action>?<query>.
+			route, _, _ := strings.Cut(test.uri, "?")
+			parts := strings.Split(route, "/")
+			id, action := parts[2], parts[3]
+
+			response := send(t, remote, http.MethodPost, test.uri, gin.Params{{Key: "id", Value: id}})
+
+			if test.status != http.StatusOK {
+				if response.Kind != communication.KindError || response.Status != test.status {
+					t.Fatalf("got %s with status %d (%s), want status %d", response.Kind, response.Status, response.Error, test.status)
+				}
+				return
+			}
+
+			if response.Kind != communication.KindResponse {
+				t.Fatalf("got %s with status %d (%s), want a response", response.Kind, response.Status, response.Error)
+			}
+
+			var got state
+			if err := json.Unmarshal(response.Payload, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != test.state {
+				t.Fatalf("got state %+v, want %+v", got, test.state)
+			}
+
+			engine.mu.Lock()
+			defer engine.mu.Unlock()
+
+			query := engine.actions[action]
+			for key, value := range test.query {
+				if query.Get(key) != value {
+					t.Fatalf("the daemon got %s=%q, want %q", key, query.Get(key), value)
+				}
+			}
+		})
+	}
+}
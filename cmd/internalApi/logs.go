@@ -0,0 +1,53 @@
+package internalapi
+
+import (
+	"net/http"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/jhonjoao/remote-containers/cmd/api"
+)
+
+func containerLogs(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	query := queryValues(w)
+
+	options := container.LogsOptions{
+		Since: query.Get("since"),
+		Until: query.Get("until"),
+		Tail:  query.Get("tail"),
+	}
+
+	var err error
+	for name, option := range map[string]*bool{
+		"follow":     &options.Follow,
+		"timestamps": &options.Timestamps,
+		"stdout":     &options.ShowStdout,
+		"stderr":     &options.ShowStderr,
+	} {
+		*option, err = queryBool(query, name)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if !query.Has("stdout") && !query.Has("stderr") {
+		options.ShowStdout = true
+		options.ShowStderr = true
+	}
+
+	out := chunkWriter{w: w}
+
+	err = dockerClient.ContainerLogs(w.Ctx, containerId, options, out, out)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	if w.Ctx.Err() != nil {
+		return
+	}
+
+	respondEnd(w)
+}
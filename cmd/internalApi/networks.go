@@ -0,0 +1,131 @@
+package internalapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jhonjoao/remote-containers/cmd/api"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+)
+
+func createNetwork(w *api.TransactionRequest) {
+
+	var request docker.NetworkCreateRequest
+
+	err := json.Unmarshal(w.Body, &request)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	response, err := dockerClient.CreateNetwork(request)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusCreated, response)
+}
+
+func listNetworks(w *api.TransactionRequest) {
+
+	networkFilters, err := queryFilters(queryValues(w))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := dockerClient.ListNetworks(networkFilters)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, response)
+}
+
+func inspectNetwork(w *api.TransactionRequest) {
+
+	networkId, _ := w.Params.Get("id")
+
+	response, err := dockerClient.InspectNetwork(networkId)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, response)
+}
+
+func removeNetwork(w *api.TransactionRequest) {
+
+	networkId, _ := w.Params.Get("id")
+
+	err := dockerClient.RemoveNetwork(networkId)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, "Ok")
+}
+
+func pruneNetworks(w *api.TransactionRequest) {
+
+	pruneFilters, err := queryFilters(queryValues(w))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := dockerClient.PruneNetworks(pruneFilters)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, report)
+}
+
+func connectNetwork(w *api.TransactionRequest) {
+
+	networkId, _ := w.Params.Get("id")
+
+	var request docker.NetworkConnectRequest
+
+	err := json.Unmarshal(w.Body, &request)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	err = dockerClient.ConnectNetwork(networkId, request)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, "Ok")
+}
+
+func disconnectNetwork(w *api.TransactionRequest) {
+
+	networkId, _ := w.Params.Get("id")
+
+	var request docker.NetworkDisconnectRequest
+
+	err := json.Unmarshal(w.Body, &request)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	err = dockerClient.DisconnectNetwork(networkId, request)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, "Ok")
+}
@@ -0,0 +1,84 @@
+package internalapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jhonjoao/remote-containers/cmd/api"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+)
+
+func containerStats(w *api.TransactionRequest) {
+	containerId, _ := w.Params.Get("id")
+
+	query := queryValues(w)
+
+	stream, err := queryBool(query, "stream")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	raw, err := queryBool(query, "raw")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sample := func(stats types.StatsJSON) docker.StatsSample {
+		result := docker.StatsSample{Summary: docker.Summarize(stats)}
+		if raw {
+			result.Raw = &stats
+		}
+		return result
+	}
+
+	if !stream {
+		var result docker.StatsSample
+
+		err = dockerClient.ContainerStats(w.Ctx, containerId, false, func(stats types.StatsJSON) error {
+			result = sample(stats)
+			return nil
+		})
+		if err != nil {
+			respondDockerError(w, err)
+			return
+		}
+
+		respond(w, http.StatusOK, result)
+		return
+	}
+
+	err = dockerClient.ContainerStats(w.Ctx, containerId, true, func(stats types.StatsJSON) error {
+		line, err := json.Marshal(sample(stats))
+		if err != nil {
+			return err
+		}
+
+		return respondChunk(w, append(line, '\n'))
+	})
+	if err != nil {
+		if w.Ctx.Err() == nil {
+			respondDockerError(w, err)
+		}
+		return
+	}
+
+	if w.Ctx.Err() != nil {
+		return
+	}
+
+	respondEnd(w)
+}
+
+func allStats(w *api.TransactionRequest) {
+
+	result, err := dockerClient.AllStats(w.Ctx)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, result)
+}
@@ -0,0 +1,105 @@
+package internalapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/jhonjoao/remote-containers/cmd/api"
+	"github.com/jhonjoao/remote-containers/internal/docker"
+)
+
+func createVolume(w *api.TransactionRequest) {
+
+	var request docker.VolumeCreateRequest
+
+	if len(w.Body) > 0 {
+		err := json.Unmarshal(w.Body, &request)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+			return
+		}
+	}
+
+	response, err := dockerClient.CreateVolume(request)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusCreated, response)
+}
+
+func listVolumes(w *api.TransactionRequest) {
+
+	volumeFilters, err := queryFilters(queryValues(w))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := dockerClient.ListVolumes(volumeFilters)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, response)
+}
+
+func inspectVolume(w *api.TransactionRequest) {
+
+	name, _ := w.Params.Get("name")
+
+	response, err := dockerClient.InspectVolume(name)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, response)
+}
+
+func removeVolume(w *api.TransactionRequest) {
+
+	name, _ := w.Params.Get("name")
+
+	force, err := queryBool(queryValues(w), "force")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = dockerClient.RemoveVolume(name, force)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, "Ok")
+}
+
+func pruneVolumes(w *api.TransactionRequest) {
+
+	query := queryValues(w)
+
+	all, err := queryBool(query, "all")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pruneFilters, err := queryFilters(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := dockerClient.PruneVolumes(all, pruneFilters)
+	if err != nil {
+		respondDockerError(w, err)
+		return
+	}
+
+	respond(w, http.StatusOK, report)
+}
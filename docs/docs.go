@@ -60,6 +60,13 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             },
@@ -78,6 +85,24 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "kill the container if it is running",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove anonymous volumes of the container",
+                        "name": "removeVolumes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove the container links",
+                        "name": "removeLinks",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -87,33 +112,150 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "invalid query parameter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "container is running",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/containers/create": {
-            "post": {
+        "/containers/:id/archive": {
+            "get": {
+                "description": "The archive is streamed from the remote machine as the\ndaemon produces it, so large files are never held in memory.",
                 "consumes": [
-                    "application/json"
+                    "*/*"
+                ],
+                "produces": [
+                    "application/x-tar"
+                ],
+                "summary": "downloads a path of a Docker container as a tar archive",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "file or directory inside the container",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tar archive",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "path is missing",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container or path not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "The request body is streamed to the remote machine and\nextracted while it is being uploaded, at the pace the daemon\nreads it.",
+                "consumes": [
+                    "application/x-tar"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "creates a new Docker container",
+                "summary": "extracts a tar archive into a path of a Docker container",
                 "parameters": [
                     {
-                        "description": "body data",
-                        "name": "data",
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "directory inside the container to extract the archive into",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "fail instead of replacing a directory with a file or the other way around",
+                        "name": "noOverwriteDirNonDir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "keep the owner of the files from the archive",
+                        "name": "copyUIDGID",
+                        "in": "query"
+                    },
+                    {
+                        "description": "tar archive",
+                        "name": "archive",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/docker.CreateRequest"
+                            "type": "string"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "ok",
+                        "description": "extracted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "path is missing or the archive is invalid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "the container file system is read-only",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container or path not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -122,18 +264,135 @@ const docTemplate = `{
                 }
             }
         },
-        "/containers/list": {
+        "/containers/:id/exec": {
             "get": {
+                "description": "Upgrades to a WebSocket. Binary messages start with a channel\nbyte: 0 stdin, 1 stdout, 2 stderr, 3 exit status (JSON), 4 resize\n(JSON with height and width). A stdin message without data closes stdin.",
+                "summary": "opens an interactive exec session in a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "command and arguments",
+                        "name": "cmd",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allocate a TTY",
+                        "name": "tty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "KEY=value environment variables",
+                        "name": "env",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user to run the command as",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "working directory of the command",
+                        "name": "workingDir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "initial TTY height",
+                        "name": "height",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "initial TTY width",
+                        "name": "width",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "switching protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "could not reach the other node",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/:id/kill": {
+            "post": {
                 "consumes": [
                     "*/*"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "lists all Docker containers",
+                "summary": "sends a signal to a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "signal to send, SIGKILL by default",
+                        "name": "signal",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "ok",
+                        "description": "state of the container",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "container is not running",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -141,25 +400,2258 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "docker.CreateRequest": {
-            "type": "object",
-            "required": [
-                "image"
-            ],
-            "properties": {
-                "cmd": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        },
+        "/containers/:id/logs": {
+            "get": {
+                "description": "Sends the logs as a chunked text/plain response, or as\nServer-Sent Events when the client accepts text/event-stream.\nWhen the logs fail after they started, the event stream ends\nwith an error event and a plain response is cut off.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "text/plain",
+                    "text/event-stream"
+                ],
+                "summary": "streams the logs of a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "keep streaming new log lines",
+                        "name": "follow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "number of lines to show from the end, or all",
+                        "name": "tail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "show logs since a timestamp or relative time (e.g. 10m)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "show logs before a timestamp or relative time",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "prefix every line with its timestamp",
+                        "name": "timestamps",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include stdout (default true unless stderr is set)",
+                        "name": "stdout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include stderr (default true unless stdout is set)",
+                        "name": "stderr",
+                        "in": "query"
                     }
-                },
-                "image": {
-                    "type": "string"
-                },
-                "name": {
+                ],
+                "responses": {
+                    "200": {
+                        "description": "log output",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/:id/pause": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "pauses all processes of a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "state of the container",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "container is not running",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/:id/restart": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "restarts a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "seconds to wait before killing the container",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "state of the container",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid timeout",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/:id/start": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "starts a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "state of the container",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/:id/stats": {
+            "get": {
+                "description": "Returns one sample, or with stream=true keeps sending samples\nas newline-delimited JSON (Server-Sent Events when the client\naccepts text/event-stream). Each sample has a computed summary\nand, with raw=true, Docker's own stats.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "summary": "resource usage of a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "keep streaming samples",
+                        "name": "stream",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include Docker's raw stats",
+                        "name": "raw",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stats sample",
+                        "schema": {
+                            "$ref": "#/definitions/docker.StatsSample"
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/:id/stop": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "stops a Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "seconds to wait before killing the container",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "state of the container",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid timeout",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/:id/unpause": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "resumes all processes of a paused Docker container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "state of the container",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "container is not paused",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "creates a new Docker container",
+                "parameters": [
+                    {
+                        "description": "body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/docker.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "registry denied access to the image",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "name already in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/list": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "lists all Docker containers",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/discovery": {
+            "get": {
+                "description": "Nodes are found with mDNS. Those advertising the same cluster\nname have inCluster set.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "lists the nodes found on the local network that are not connected",
+                "responses": {
+                    "200": {
+                        "description": "discovered peers",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/peers.Discovered"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Each event is sent with its Docker type as the SSE event name\nand its time and object ID as the SSE id, so reconnecting\nclients resume with Last-Event-ID without getting it twice.\nThe subscription to the other node is re-established\nautomatically when it drops.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "streams Docker events of the remote machine as Server-Sent Events",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "event type, e.g. container",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "event action, e.g. die, start, oom",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "container id or name",
+                        "name": "container",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "label or label=value",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "other key=value event filters",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "replay events since a timestamp",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/:id": {
+            "get": {
+                "description": "References containing \"/\" must be URL encoded, e.g. library%2Fnginx:latest.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "inspects a Docker image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "image id or reference",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "removes a Docker image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "image id or reference",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove the image even if containers use it",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "keep untagged parent images",
+                        "name": "noprune",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "image is in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/images/:id/tag": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "tags a Docker image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "image id or reference",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "repository of the new tag",
+                        "name": "repo",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "tag name, latest by default",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid reference",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/images/list": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "lists the Docker images of the remote machine",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "include intermediate images",
+                        "name": "all",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key=value filters, e.g. dangling=true or reference=nginx",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/images/prune": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "removes unused Docker images",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "remove every image without containers, not only dangling ones",
+                        "name": "all",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key=value filters, e.g. until=24h or label=env=dev",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted images and reclaimed space",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/images/pull": {
+            "post": {
+                "description": "Streams the progress messages of the daemon as\nnewline-delimited JSON, or as Server-Sent Events when the\nclient accepts text/event-stream. Registry credentials are\nonly forwarded to the remote Docker daemon.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "summary": "pulls an image on the remote machine",
+                "parameters": [
+                    {
+                        "description": "image to pull",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/docker.PullRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "progress messages",
+                        "schema": {
+                            "$ref": "#/definitions/docker.PullProgress"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "registry denied access",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/networks/:id": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "inspects a Docker network",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "network id or name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "network not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "removes a Docker network",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "network id or name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "network not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "network has active endpoints",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/networks/:id/connect": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "connects a container to a Docker network",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "network id or name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "container to connect",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/docker.NetworkConnectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "network or container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/networks/:id/disconnect": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "disconnects a container from a Docker network",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "network id or name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "container to disconnect",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/docker.NetworkDisconnectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "network or container not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/networks/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "creates a Docker network",
+                "parameters": [
+                    {
+                        "description": "network options",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/docker.NetworkCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "name already in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/networks/list": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "lists Docker networks",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key=value filters, e.g. driver=bridge or name=backend",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/networks/prune": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "removes unused Docker networks",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key=value filters, e.g. until=24h",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted networks",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/pairing/join": {
+            "post": {
+                "description": "Both nodes authorize each other for good, and this node\nconnects to the other one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "joins the node that issued a pairing token",
+                "parameters": [
+                    {
+                        "description": "token, alias and role of the other node",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.JoinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the other node",
+                        "schema": {
+                            "$ref": "#/definitions/trust.Entry"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid token or role",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "token refused, or not sent from this machine",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "body is not JSON",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "502": {
+                        "description": "other node unreachable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/pairing/tokens": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "lists the pairing tokens that can still be used",
+                "responses": {
+                    "200": {
+                        "description": "tokens, without the token string",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/pairing.Token"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "not sent from this machine",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The token holds the address of this node and a secret. The\nnode that joins with it is authorized on this one, and\nauthorizes it back.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "issues a one-time pairing token",
+                "parameters": [
+                    {
+                        "description": "name and role of the joining node, lifetime of the token",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.IssueTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "token",
+                        "schema": {
+                            "$ref": "#/definitions/pairing.Token"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "not sent from this machine",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "body is not JSON",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/pairing/tokens/:id": {
+            "delete": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "revokes a pairing token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "revoked"
+                    },
+                    "403": {
+                        "description": "not sent from this machine",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "no such token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/peers": {
+            "get": {
+                "description": "Every other route can be sent to one of them by prefixing it\nwith /peers/{id or alias}, e.g. /peers/db/containers/list.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "lists the connected peers",
+                "responses": {
+                    "200": {
+                        "description": "connected peers",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/api.PeerInfo"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/peers/:peer": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "shows a connected peer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "peer ID or alias",
+                        "name": "peer",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "peer",
+                        "schema": {
+                            "$ref": "#/definitions/api.PeerInfo"
+                        }
+                    },
+                    "404": {
+                        "description": "peer not connected",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "resource usage of all running containers",
+                "responses": {
+                    "200": {
+                        "description": "per container summaries and totals",
+                        "schema": {
+                            "$ref": "#/definitions/docker.AggregateStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "shows the bound addresses and connections of this node",
+                "responses": {
+                    "200": {
+                        "description": "status of the node",
+                        "schema": {
+                            "$ref": "#/definitions/api.NodeStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/volumes/:name": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "inspects a Docker volume",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "volume name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "volume not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "removes a Docker volume",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "volume name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove the volume even if it is in use",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "volume not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "volume is in use",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/volumes/create": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "creates a Docker volume",
+                "parameters": [
+                    {
+                        "description": "volume options",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/docker.VolumeCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/volumes/list": {
+            "get": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "lists Docker volumes",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key=value filters, e.g. dangling=true or label=env=dev",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/volumes/prune": {
+            "post": {
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "removes unused Docker volumes",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "also remove unused named volumes",
+                        "name": "all",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key=value filters, e.g. label=env=dev",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted volumes and reclaimed space",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.IssueTokenRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name and Role are those the joining node is authorized with.",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "ttl": {
+                    "description": "Ttl is how long the token can be used, e.g. \"10m\". It defaults to\n10 minutes.",
+                    "type": "string"
+                }
+            }
+        },
+        "api.JoinRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name and Role are those the node that issued the token is authorized\nwith here. Role defaults to viewer.",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.NodeStatus": {
+            "type": "object",
+            "properties": {
+                "api": {
+                    "description": "Api is the address the HTTP API is bound to.",
+                    "type": "string"
+                },
+                "connectedPeers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "events": {
+                    "description": "Events are the latest connections, disconnections and failed dials.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/peers.Event"
+                    }
+                },
+                "listenAddrs": {
+                    "description": "ListenAddrs are the addresses the libp2p node is bound to.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "multiaddrs": {
+                    "description": "Multiaddrs are the addresses other nodes can connect to this one\nwith.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "peerId": {
+                    "type": "string"
+                },
+                "supervised": {
+                    "description": "Supervised are the peers this node dialed and reconnects to when\ntheir connection drops.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/peers.Supervision"
+                    }
+                }
+            }
+        },
+        "api.PeerInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address is the one the connection to the peer uses, Addrs all those\nthe peer is known to listen on.",
+                    "type": "string"
+                },
+                "addrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "alias": {
+                    "type": "string"
+                },
+                "connectedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "latencyMs": {
+                    "type": "number"
+                },
+                "role": {
+                    "description": "Role is what the peer may do on this node, admin or viewer.",
+                    "type": "string"
+                }
+            }
+        },
+        "docker.AggregateStats": {
+            "type": "object",
+            "properties": {
+                "containers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docker.StatsSummary"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/docker.StatsTotal"
+                }
+            }
+        },
+        "docker.CreateRequest": {
+            "type": "object",
+            "required": [
+                "image"
+            ],
+            "properties": {
+                "auth": {
+                    "$ref": "#/definitions/docker.RegistryAuth"
+                },
+                "cmd": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cpus": {
+                    "description": "CPUs is the number of CPUs the container may use, e.g. 1.5.",
+                    "type": "number"
+                },
+                "entrypoint": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "env": {
+                    "description": "Env entries use the KEY=value form.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "image": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "memory": {
+                    "description": "Memory is the memory limit in bytes.",
+                    "type": "integer"
+                },
+                "mounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docker.Mount"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "network": {
+                    "type": "string"
+                },
+                "networkAliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docker.PortBinding"
+                    }
+                },
+                "pull": {
+                    "description": "Pull tells whether to pull the image before creating the container.\nIt defaults to never.",
+                    "type": "string",
+                    "enum": [
+                        "missing",
+                        "always",
+                        "never"
+                    ]
+                },
+                "restartPolicy": {
+                    "$ref": "#/definitions/docker.RestartPolicy"
+                },
+                "user": {
+                    "type": "string"
+                },
+                "volumes": {
+                    "description": "Volumes lists container paths that get an anonymous volume.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "workingDir": {
+                    "type": "string"
+                }
+            }
+        },
+        "docker.Mount": {
+            "type": "object",
+            "required": [
+                "target"
+            ],
+            "properties": {
+                "readOnly": {
+                    "type": "boolean"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is one of bind, volume or tmpfs. It defaults to bind when the\nsource is an absolute path and to volume otherwise.",
+                    "type": "string",
+                    "enum": [
+                        "bind",
+                        "volume",
+                        "tmpfs"
+                    ]
+                }
+            }
+        },
+        "docker.NetworkConnectRequest": {
+            "type": "object",
+            "required": [
+                "container"
+            ],
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "container": {
+                    "type": "string"
+                },
+                "ipv4Address": {
+                    "type": "string"
+                }
+            }
+        },
+        "docker.NetworkCreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "attachable": {
+                    "type": "boolean"
+                },
+                "driver": {
+                    "type": "string"
+                },
+                "enableIPv6": {
+                    "type": "boolean"
+                },
+                "gateway": {
+                    "type": "string"
+                },
+                "internal": {
+                    "type": "boolean"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "options": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "subnet": {
+                    "description": "Subnet and Gateway use CIDR and IP notation, e.g. 172.28.0.0/16.",
+                    "type": "string"
+                }
+            }
+        },
+        "docker.NetworkDisconnectRequest": {
+            "type": "object",
+            "required": [
+                "container"
+            ],
+            "properties": {
+                "container": {
+                    "type": "string"
+                },
+                "force": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "docker.PortBinding": {
+            "type": "object",
+            "required": [
+                "containerPort"
+            ],
+            "properties": {
+                "containerPort": {
+                    "description": "ContainerPort is a port number with an optional protocol, e.g. \"80/udp\".",
+                    "type": "string"
+                },
+                "hostIp": {
+                    "type": "string"
+                },
+                "hostPort": {
+                    "type": "string"
+                }
+            }
+        },
+        "docker.PullProgress": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "errorDetail": {
+                    "type": "object",
+                    "properties": {
+                        "code": {
+                            "type": "integer"
+                        },
+                        "message": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "string"
+                },
+                "progressDetail": {
+                    "type": "object",
+                    "properties": {
+                        "current": {
+                            "type": "integer"
+                        },
+                        "total": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "docker.PullRequest": {
+            "type": "object",
+            "required": [
+                "image"
+            ],
+            "properties": {
+                "auth": {
+                    "$ref": "#/definitions/docker.RegistryAuth"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "Platform selects the image variant, e.g. linux/arm64.",
+                    "type": "string"
+                }
+            }
+        },
+        "docker.RegistryAuth": {
+            "type": "object",
+            "properties": {
+                "identityToken": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "registryToken": {
+                    "type": "string"
+                },
+                "serverAddress": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "docker.RestartPolicy": {
+            "type": "object",
+            "properties": {
+                "maximumRetryCount": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "enum": [
+                        "no",
+                        "always",
+                        "on-failure",
+                        "unless-stopped"
+                    ]
+                }
+            }
+        },
+        "docker.StatsSample": {
+            "type": "object",
+            "properties": {
+                "raw": {
+                    "type": "object"
+                },
+                "summary": {
+                    "$ref": "#/definitions/docker.StatsSummary"
+                }
+            }
+        },
+        "docker.StatsSummary": {
+            "type": "object",
+            "properties": {
+                "blockRead": {
+                    "type": "integer"
+                },
+                "blockWrite": {
+                    "type": "integer"
+                },
+                "cpuPercent": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "memoryLimit": {
+                    "type": "integer"
+                },
+                "memoryPercent": {
+                    "type": "number"
+                },
+                "memoryUsage": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "networkRx": {
+                    "type": "integer"
+                },
+                "networkTx": {
+                    "type": "integer"
+                },
+                "pids": {
+                    "type": "integer"
+                },
+                "read": {
+                    "type": "string"
+                }
+            }
+        },
+        "docker.StatsTotal": {
+            "type": "object",
+            "properties": {
+                "blockRead": {
+                    "type": "integer"
+                },
+                "blockWrite": {
+                    "type": "integer"
+                },
+                "containers": {
+                    "type": "integer"
+                },
+                "cpuPercent": {
+                    "type": "number"
+                },
+                "memoryUsage": {
+                    "type": "integer"
+                },
+                "networkRx": {
+                    "type": "integer"
+                },
+                "networkTx": {
+                    "type": "integer"
+                },
+                "pids": {
+                    "type": "integer"
+                }
+            }
+        },
+        "docker.VolumeCreateRequest": {
+            "type": "object",
+            "properties": {
+                "driver": {
+                    "type": "string"
+                },
+                "driverOpts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "Name is generated by the daemon when empty.",
+                    "type": "string"
+                }
+            }
+        },
+        "pairing.Token": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "peers.Discovered": {
+            "type": "object",
+            "properties": {
+                "addrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "inCluster": {
+                    "description": "InCluster is set when the node advertises the same cluster name as\nthis one.",
+                    "type": "boolean"
+                },
+                "lastSeen": {
+                    "type": "string"
+                },
+                "peer": {
+                    "type": "string"
+                }
+            }
+        },
+        "peers.Event": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "peer": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/peers.EventType"
+                }
+            }
+        },
+        "peers.EventType": {
+            "type": "string",
+            "enum": [
+                "connected",
+                "disconnected",
+                "dial-failed",
+                "reconnected"
+            ],
+            "x-enum-varnames": [
+                "EventConnected",
+                "EventDisconnected",
+                "EventDialFailed",
+                "EventReconnected"
+            ]
+        },
+        "peers.Supervision": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "connected": {
+                    "type": "boolean"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextDial": {
+                    "type": "string"
+                },
+                "peer": {
+                    "type": "string"
+                },
+                "reconnects": {
+                    "type": "integer"
+                }
+            }
+        },
+        "trust.Entry": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
                     "type": "string"
                 }
             }
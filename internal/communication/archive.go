@@ -0,0 +1,30 @@
+package communication
+
+import "github.com/libp2p/go-libp2p/core/protocol"
+
+// ArchiveProtocol is the libp2p protocol of the dedicated stream opened for
+// every archive download or upload, so the flow control of the stream keeps
+// the sending side at the pace of the Docker daemon. The opening side sends
+// a FrameArchiveStart frame. For a download the other side answers with the
+// tar archive in FrameData frames; for an upload the opening side follows
+// with the tar archive as is and closes its side of the stream. Either way
+// the other side ends with a FrameArchiveEnd frame before closing the
+// stream, so an archive cut short is never taken for a whole one.
+const ArchiveProtocol protocol.ID = "/remote-containers/archive/1.0.0"
+
+type ArchiveStart struct {
+	ContainerID string `json:"containerId"`
+	Path        string `json:"path"`
+	// Upload extracts the archive that follows into Path instead of
+	// sending Path back.
+	Upload               bool `json:"upload"`
+	NoOverwriteDirNonDir bool `json:"noOverwriteDirNonDir"`
+	CopyUIDGID           bool `json:"copyUIDGID"`
+}
+
+// ArchiveEnd is the outcome of the transfer, with the HTTP status code that
+// matches it.
+type ArchiveEnd struct {
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,76 @@
+package communication
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Kind string
+
+const (
+	KindRequest     Kind = "request"
+	KindResponse    Kind = "response"
+	KindError       Kind = "error"
+	KindEvent       Kind = "event"
+	KindStreamChunk Kind = "stream-chunk"
+	// KindCancel asks the remote node to stop working on a request, e.g.
+	// because the HTTP client following a stream went away.
+	KindCancel Kind = "cancel"
+)
+
+// StreamChunkSize is the largest payload sent in a single stream chunk.
+const StreamChunkSize = 32 * 1024
+
+// Envelope is the message exchanged between nodes. The kind says how the
+// payload must be read, so a response is never mistaken for a request.
+type Envelope struct {
+	Id      string
+	Kind    Kind
+	Status  int
+	Error   string
+	Payload []byte
+}
+
+func (e Envelope) toFrame() Frame {
+	header := map[string]string{
+		"id":   e.Id,
+		"kind": string(e.Kind),
+	}
+
+	if e.Status != 0 {
+		header["status"] = strconv.Itoa(e.Status)
+	}
+
+	if e.Error != "" {
+		header["error"] = e.Error
+	}
+
+	return Frame{
+		Type:    FrameData,
+		Header:  header,
+		Payload: e.Payload,
+	}
+}
+
+func envelopeFromFrame(frame Frame) (Envelope, error) {
+	envelope := Envelope{
+		Id:      frame.Header["id"],
+		Kind:    Kind(frame.Header["kind"]),
+		Error:   frame.Header["error"],
+		Payload: frame.Payload,
+	}
+
+	if envelope.Kind == "" {
+		return Envelope{}, fmt.Errorf("envelope %q has no kind", envelope.Id)
+	}
+
+	if status, ok := frame.Header["status"]; ok {
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			return Envelope{}, fmt.Errorf("envelope %q has invalid status %q", envelope.Id, status)
+		}
+		envelope.Status = code
+	}
+
+	return envelope, nil
+}
@@ -0,0 +1,66 @@
+package communication
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+
+	envelopes := []Envelope{
+		{Id: "1", Kind: KindRequest, Payload: []byte(`{"Method":"GET"}`)},
+		{Id: "2", Kind: KindResponse, Status: 201, Payload: []byte("END_OF_TRANSMISSION")},
+		{Id: "3", Kind: KindError, Status: 404, Error: "No such container: web"},
+		{Id: "4", Kind: KindStreamChunk, Payload: []byte{0, 1, 2, 0xFF}},
+		{Id: "5", Kind: KindCancel},
+	}
+
+	for _, envelope := range envelopes {
+		var buf bytes.Buffer
+		if err := WriteFrame(&buf, envelope.toFrame()); err != nil {
+			t.Fatal(err)
+		}
+
+		frame, err := ReadFrame(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := envelopeFromFrame(frame)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Id != envelope.Id || got.Kind != envelope.Kind || got.Status != envelope.Status ||
+			got.Error != envelope.Error || !bytes.Equal(got.Payload, envelope.Payload) {
+			t.Fatalf("got %+v, want %+v", got, envelope)
+		}
+	}
+}
+
+func TestEnvelopeHeaderOmitsEmptyFields(t *testing.T) {
+
+	frame := Envelope{Id: "1", Kind: KindRequest}.toFrame()
+
+	want := map[string]string{"id": "1", "kind": "request"}
+	if !reflect.DeepEqual(frame.Header, want) {
+		t.Fatalf("got header %v, want %v", frame.Header, want)
+	}
+}
+
+func TestEnvelopeFromMalformedFrame(t *testing.T) {
+
+	frames := map[string]Frame{
+		"no kind":        {Type: FrameData, Header: map[string]string{"id": "1"}},
+		"invalid status": {Type: FrameData, Header: map[string]string{"id": "1", "kind": "response", "status": "ok"}},
+	}
+
+	for name, frame := range frames {
+		t.Run(name, func(t *testing.T) {
+			if _, err := envelopeFromFrame(frame); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
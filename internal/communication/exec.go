@@ -0,0 +1,90 @@
+package communication
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// ExecProtocol is the libp2p protocol of the dedicated stream opened for
+// every interactive exec session. The opening side sends a FrameExecStart
+// frame, then stdin and resize frames; the other side answers with stdout
+// and stderr frames and a final FrameExit before closing the stream.
+const ExecProtocol protocol.ID = "/remote-containers/exec/1.0.0"
+
+type ExecStart struct {
+	ContainerID string   `json:"containerId"`
+	Cmd         []string `json:"cmd"`
+	Tty         bool     `json:"tty"`
+	Env         []string `json:"env"`
+	User        string   `json:"user"`
+	WorkingDir  string   `json:"workingDir"`
+	Height      uint     `json:"height"`
+	Width       uint     `json:"width"`
+}
+
+type ExecResize struct {
+	Height uint `json:"height"`
+	Width  uint `json:"width"`
+}
+
+type ExecExit struct {
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// FrameWriter serializes frames written by several goroutines to the same
+// stream.
+type FrameWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+func (f *FrameWriter) WriteFrame(frame Frame) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return WriteFrame(f.w, frame)
+}
+
+func (f *FrameWriter) WriteJSON(frameType FrameType, value interface{}) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return f.WriteFrame(Frame{Type: frameType, Payload: payload})
+}
+
+// Output returns an io.Writer that sends everything written to it as
+// frames of the given type.
+func (f *FrameWriter) Output(frameType FrameType) io.Writer {
+	return frameOutput{writer: f, frameType: frameType}
+}
+
+type frameOutput struct {
+	writer    *FrameWriter
+	frameType FrameType
+}
+
+func (o frameOutput) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		size := min(len(p)-written, StreamChunkSize)
+
+		err := o.writer.WriteFrame(Frame{Type: o.frameType, Payload: p[written : written+size]})
+		if err != nil {
+			return written, err
+		}
+
+		written += size
+	}
+
+	return written, nil
+}
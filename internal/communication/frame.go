@@ -0,0 +1,228 @@
+package communication
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Frame layout on the wire (all integers big endian):
+//
+//	magic      [2]byte  "RC"
+//	version    uint8
+//	type       uint8
+//	headerLen  uint16
+//	payloadLen uint32
+//	header     [headerLen]byte
+//	payload    [payloadLen]byte
+//
+// The header is a list of key/value pairs, each encoded as a uint16 length
+// followed by the bytes of the key and then of the value.
+const (
+	FrameVersion   uint8 = 1
+	FramePrefixLen       = 10
+	MaxHeaderSize        = 0xFFFF
+	MaxPayloadSize       = 64 * 1024 * 1024
+	// payloadChunkSize is how much of a payload is allocated before its
+	// bytes arrive.
+	payloadChunkSize = 64 * 1024
+)
+
+var frameMagic = [2]byte{'R', 'C'}
+
+type FrameType uint8
+
+const (
+	FrameData FrameType = iota + 1
+	FrameExecStart
+	FrameStdin
+	FrameStdinClose
+	FrameStdout
+	FrameStderr
+	FrameResize
+	FrameExit
+	FrameArchiveStart
+	FrameArchiveEnd
+)
+
+var (
+	ErrBadMagic        = errors.New("frame: bad magic")
+	ErrBadVersion      = errors.New("frame: unsupported version")
+	ErrFrameTooLarge   = errors.New("frame: size exceeds limit")
+	ErrMalformedHeader = errors.New("frame: malformed header")
+)
+
+type Frame struct {
+	Type    FrameType
+	Header  map[string]string
+	Payload []byte
+}
+
+func encodeHeader(header map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf []byte
+	for _, key := range keys {
+		for _, field := range []string{key, header[key]} {
+			if len(field) > 0xFFFF {
+				return nil, ErrFrameTooLarge
+			}
+			buf = binary.BigEndian.AppendUint16(buf, uint16(len(field)))
+			buf = append(buf, field...)
+		}
+	}
+
+	if len(buf) > MaxHeaderSize {
+		return nil, ErrFrameTooLarge
+	}
+
+	return buf, nil
+}
+
+func decodeHeader(data []byte) (map[string]string, error) {
+	header := map[string]string{}
+
+	readField := func() (string, error) {
+		if len(data) < 2 {
+			return "", ErrMalformedHeader
+		}
+		size := int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+		if len(data) < size {
+			return "", ErrMalformedHeader
+		}
+		field := string(data[:size])
+		data = data[size:]
+		return field, nil
+	}
+
+	for len(data) > 0 {
+		key, err := readField()
+		if err != nil {
+			return nil, err
+		}
+		value, err := readField()
+		if err != nil {
+			return nil, err
+		}
+		header[key] = value
+	}
+
+	return header, nil
+}
+
+// MarshalFrame encodes a frame into a single buffer so it can be written
+// to the stream with one call.
+func MarshalFrame(frame Frame) ([]byte, error) {
+	header, err := encodeHeader(frame.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(frame.Payload) > MaxPayloadSize {
+		return nil, ErrFrameTooLarge
+	}
+
+	buf := make([]byte, 0, FramePrefixLen+len(header)+len(frame.Payload))
+	buf = append(buf, frameMagic[:]...)
+	buf = append(buf, FrameVersion, uint8(frame.Type))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(header)))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(frame.Payload)))
+	buf = append(buf, header...)
+	buf = append(buf, frame.Payload...)
+
+	return buf, nil
+}
+
+func WriteFrame(w io.Writer, frame Frame) error {
+	buf, err := MarshalFrame(frame)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf)
+	return err
+}
+
+// ReadFrame reads exactly one frame from r. It never reads past the end of
+// the frame, so consecutive frames in the same read are kept intact.
+func ReadFrame(r io.Reader) (Frame, error) {
+	var prefix [FramePrefixLen]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return Frame{}, err
+	}
+
+	if prefix[0] != frameMagic[0] || prefix[1] != frameMagic[1] {
+		return Frame{}, ErrBadMagic
+	}
+
+	if prefix[2] != FrameVersion {
+		return Frame{}, fmt.Errorf("%w: %d", ErrBadVersion, prefix[2])
+	}
+
+	headerLen := int(binary.BigEndian.Uint16(prefix[4:6]))
+	payloadLen := int(binary.BigEndian.Uint32(prefix[6:10]))
+
+	if payloadLen > MaxPayloadSize {
+		return Frame{}, ErrFrameTooLarge
+	}
+
+	headerData := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, headerData); err != nil {
+		return Frame{}, unexpectedEOF(err)
+	}
+
+	header, err := decodeHeader(headerData)
+	if err != nil {
+		return Frame{}, err
+	}
+
+	// The payload is read in chunks so a peer announcing a large payload
+	// it never sends can't make us allocate it upfront.
+	payload := bytes.NewBuffer(make([]byte, 0, min(payloadLen, payloadChunkSize)))
+	if _, err := io.CopyN(payload, r, int64(payloadLen)); err != nil {
+		return Frame{}, unexpectedEOF(err)
+	}
+
+	return Frame{
+		Type:    FrameType(prefix[3]),
+		Header:  header,
+		Payload: payload.Bytes(),
+	}, nil
+}
+
+// unexpectedEOF reports a frame cut short, as opposed to a stream that
+// ended between two frames.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// DecodeFrames decodes every complete frame in data and returns the unread
+// remainder. It is the entry point used when fuzzing the decoder.
+func DecodeFrames(data []byte) ([]Frame, []byte, error) {
+	var frames []Frame
+
+	r := bytes.NewReader(data)
+	for {
+		offset := len(data) - r.Len()
+
+		frame, err := ReadFrame(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return frames, data[offset:], nil
+		}
+		if err != nil {
+			return frames, nil, err
+		}
+		frames = append(frames, frame)
+	}
+}
@@ -0,0 +1,165 @@
+package communication
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+
+	payloads := map[string][]byte{
+		"empty":          {},
+		"old end marker": []byte("before END_OF_TRANSMISSION after"),
+		"marker only":    []byte("END_OF_TRANSMISSION"),
+		"binary":         {0x00, 'R', 'C', 0x01, 0xFF, 0x00, 0x00, 0x00, 0x10},
+		"large":          bytes.Repeat([]byte("END_OF_TRANSMISSION\x00"), 10000),
+	}
+
+	for name, payload := range payloads {
+		t.Run(name, func(t *testing.T) {
+
+			frame := Frame{
+				Type:    FrameStdout,
+				Header:  map[string]string{"id": "42", "marker": "END_OF_TRANSMISSION"},
+				Payload: payload,
+			}
+
+			var buf bytes.Buffer
+			if err := WriteFrame(&buf, frame); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ReadFrame(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Type != frame.Type || !reflect.DeepEqual(got.Header, frame.Header) || !bytes.Equal(got.Payload, frame.Payload) {
+				t.Fatalf("got %+v, want %+v", got, frame)
+			}
+
+			if buf.Len() != 0 {
+				t.Fatalf("%d bytes left after the frame", buf.Len())
+			}
+		})
+	}
+}
+
+func TestDecodeFramesInOneRead(t *testing.T) {
+
+	first, _ := MarshalFrame(Frame{Type: FrameData, Payload: []byte("END_OF_TRANSMISSION")})
+	second, _ := MarshalFrame(Frame{Type: FrameExit, Header: map[string]string{"a": "b"}, Payload: []byte{0}})
+	third, _ := MarshalFrame(Frame{Type: FrameStdin, Payload: []byte("partial")})
+
+	data := append(append(append([]byte{}, first...), second...), third[:len(third)-3]...)
+
+	frames, rest, err := DecodeFrames(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+	if frames[0].Type != FrameData || string(frames[0].Payload) != "END_OF_TRANSMISSION" {
+		t.Fatalf("unexpected first frame %+v", frames[0])
+	}
+	if frames[1].Type != FrameExit || frames[1].Header["a"] != "b" {
+		t.Fatalf("unexpected second frame %+v", frames[1])
+	}
+	if !bytes.Equal(rest, third[:len(third)-3]) {
+		t.Fatalf("got rest %q, want the partial third frame", rest)
+	}
+}
+
+func TestReadFrameRejects(t *testing.T) {
+
+	valid, _ := MarshalFrame(Frame{Type: FrameData, Payload: []byte("payload")})
+
+	badMagic := append([]byte{}, valid...)
+	badMagic[0] = 'X'
+
+	badVersion := append([]byte{}, valid...)
+	badVersion[2] = FrameVersion + 1
+
+	oversized := append([]byte{}, valid[:FramePrefixLen]...)
+	binary.BigEndian.PutUint32(oversized[6:10], MaxPayloadSize+1)
+
+	tests := map[string]struct {
+		data []byte
+		err  error
+	}{
+		"bad magic":         {badMagic, ErrBadMagic},
+		"wrong version":     {badVersion, ErrBadVersion},
+		"oversized payload": {oversized, ErrFrameTooLarge},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadFrame(bytes.NewReader(test.data))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestReadFrameTruncated(t *testing.T) {
+
+	// The prefix announces a payload far larger than what follows.
+	prefix := []byte{'R', 'C', FrameVersion, uint8(FrameData), 0, 0}
+	prefix = binary.BigEndian.AppendUint32(prefix, MaxPayloadSize)
+
+	_, err := ReadFrame(bytes.NewReader(append(prefix, "short"...)))
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestMarshalFrameTooLarge(t *testing.T) {
+
+	_, err := MarshalFrame(Frame{Type: FrameData, Payload: make([]byte, MaxPayloadSize+1)})
+	if !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("got %v, want %v", err, ErrFrameTooLarge)
+	}
+}
+
+func FuzzDecodeFrames(f *testing.F) {
+
+	seed, _ := MarshalFrame(Frame{Type: FrameData, Header: map[string]string{"k": "v"}, Payload: []byte("END_OF_TRANSMISSION")})
+	f.Add(seed)
+	f.Add(append(seed, seed...))
+	f.Add([]byte("RC"))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		frames, rest, err := DecodeFrames(data)
+		if err != nil {
+			return
+		}
+
+		// Whatever decodes cleanly has to encode back to the bytes it came
+		// from, up to the order of the header keys.
+		var buf bytes.Buffer
+		for _, frame := range frames {
+			if err := WriteFrame(&buf, frame); err != nil {
+				t.Fatalf("failed to encode a decoded frame: %v", err)
+			}
+		}
+
+		again, _, err := DecodeFrames(append(buf.Bytes(), rest...))
+		if err != nil || len(again) != len(frames) {
+			t.Fatalf("re-decoding gave %d frames and %v, want %d", len(again), err, len(frames))
+		}
+		for i := range frames {
+			if again[i].Type != frames[i].Type || !reflect.DeepEqual(again[i].Header, frames[i].Header) || !bytes.Equal(again[i].Payload, frames[i].Payload) {
+				t.Fatalf("frame %d changed after a round trip: %+v, %+v", i, frames[i], again[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package communication
+
+import "github.com/libp2p/go-libp2p/core/protocol"
+
+// PairingProtocol is the libp2p protocol a node joining another one with a
+// pairing token opens to it. The joining node sends a PairingRequest with
+// the secret of the token and the other one answers with a PairingResponse
+// before closing the stream.
+const PairingProtocol protocol.ID = "/remote-containers/pair/1.0.0"
+
+type PairingRequest struct {
+	Secret []byte `json:"secret"`
+}
+
+type PairingResponse struct {
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,188 @@
+package communication
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	RequestTimeout = 30 * time.Second
+	// StreamBuffer is how many chunks of a streaming response can be queued
+	// before the reader of the stream has to wait for the HTTP client.
+	StreamBuffer = 64
+)
+
+var (
+	ErrRequestTimeout = errors.New("timed out waiting for the remote node to answer")
+	// ErrNotWaiting is returned by Resolve for a message nobody waits for.
+	ErrNotWaiting = errors.New("nobody is waiting for the request")
+	// ErrReaderTooSlow is returned by Resolve for a stream whose reader
+	// fell StreamBuffer messages behind. The stream is failed rather than
+	// holding up the messages of every other request of the peer.
+	ErrReaderTooSlow = errors.New("the reader of the stream fell too far behind")
+)
+
+type waiter struct {
+	messages chan Envelope
+	done     chan struct{}
+	// dropped is set once the waiter was failed for being too slow, so
+	// the rest of its stream is discarded.
+	dropped bool
+}
+
+// PendingRequests routes each response read from the stream to the handler
+// waiting for the request with the same id.
+type PendingRequests struct {
+	mu      sync.Mutex
+	waiters map[string]*waiter
+	// closed is the error envelope every request gets once the peer the
+	// table belongs to is gone.
+	closed *Envelope
+}
+
+func NewPendingRequests() *PendingRequests {
+	return &PendingRequests{
+		waiters: map[string]*waiter{},
+	}
+}
+
+// Register must be called before the request is written, so a fast reply
+// can't arrive before anyone is waiting for it.
+func (p *PendingRequests) Register(id string) {
+	p.register(id, 1)
+}
+
+// RegisterStream registers a request answered by any number of stream
+// chunks followed by a response or an error.
+func (p *PendingRequests) RegisterStream(id string) <-chan Envelope {
+	return p.register(id, StreamBuffer)
+}
+
+func (p *PendingRequests) register(id string, buffer int) <-chan Envelope {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	w := &waiter{
+		messages: make(chan Envelope, buffer),
+		done:     make(chan struct{}),
+	}
+	p.waiters[id] = w
+
+	if p.closed != nil {
+		message := *p.closed
+		message.Id = id
+		w.messages <- message
+	}
+
+	return w.messages
+}
+
+// Close answers every waiting request, and every one registered later,
+// with an error envelope of the given status, so nobody keeps waiting for a
+// peer that is gone.
+func (p *PendingRequests) Close(status int, cause error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed != nil {
+		return
+	}
+
+	p.closed = &Envelope{Kind: KindError, Status: status, Error: cause.Error()}
+
+	for id, w := range p.waiters {
+		message := *p.closed
+		message.Id = id
+
+		// A stream waiter may have a full buffer, so the error is handed
+		// over without holding up the others.
+		go func(w *waiter) {
+			select {
+			case w.messages <- message:
+			case <-w.done:
+			}
+		}(w)
+	}
+}
+
+func (p *PendingRequests) Remove(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if w, ok := p.waiters[id]; ok {
+		close(w.done)
+		delete(p.waiters, id)
+	}
+}
+
+// Resolve hands the message to its waiter without ever blocking the reader
+// of the stream. It returns ErrNotWaiting when nobody is waiting for that
+// id anymore, e.g. because the request already timed out, and
+// ErrReaderTooSlow when the waiter can't take the message, in which case
+// the waiter gets an error instead and the remote node should be told to
+// stop. The waiter stays registered until its owner calls Remove.
+func (p *PendingRequests) Resolve(message Envelope) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	w, ok := p.waiters[message.Id]
+	if !ok || w.dropped {
+		return ErrNotWaiting
+	}
+
+	select {
+	case <-w.done:
+		return ErrNotWaiting
+	case w.messages <- message:
+		return nil
+	default:
+	}
+
+	w.dropped = true
+
+	failure := Envelope{
+		Id:     message.Id,
+		Kind:   KindError,
+		Status: http.StatusServiceUnavailable,
+		Error:  ErrReaderTooSlow.Error(),
+	}
+
+	// The failure is queued behind the messages already buffered.
+	go func() {
+		select {
+		case w.messages <- failure:
+		case <-w.done:
+		}
+	}()
+
+	return ErrReaderTooSlow
+}
+
+// Wait blocks until the response for id arrives, the timeout expires or ctx
+// is cancelled. The id is always removed from the table on return.
+func (p *PendingRequests) Wait(ctx context.Context, id string, timeout time.Duration) (Envelope, error) {
+	p.mu.Lock()
+	w, ok := p.waiters[id]
+	p.mu.Unlock()
+
+	defer p.Remove(id)
+
+	if !ok {
+		return Envelope{}, errors.New("request " + id + " is not registered")
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case response := <-w.messages:
+		return response, nil
+	case <-timer.C:
+		return Envelope{}, ErrRequestTimeout
+	case <-ctx.Done():
+		return Envelope{}, ctx.Err()
+	}
+}
@@ -0,0 +1,151 @@
+package communication
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPendingRequestsResolve(t *testing.T) {
+
+	pending := NewPendingRequests()
+	pending.Register("1")
+
+	if err := pending.Resolve(Envelope{Id: "1", Kind: KindResponse, Payload: []byte("ok")}); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := pending.Wait(context.Background(), "1", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response.Payload) != "ok" {
+		t.Fatalf("got payload %q", response.Payload)
+	}
+
+	if err := pending.Resolve(Envelope{Id: "1", Kind: KindResponse}); !errors.Is(err, ErrNotWaiting) {
+		t.Fatalf("got %v after Wait returned, want %v", err, ErrNotWaiting)
+	}
+}
+
+func TestPendingRequestsTimeout(t *testing.T) {
+
+	pending := NewPendingRequests()
+	pending.Register("1")
+
+	_, err := pending.Wait(context.Background(), "1", 10*time.Millisecond)
+	if !errors.Is(err, ErrRequestTimeout) {
+		t.Fatalf("got %v, want %v", err, ErrRequestTimeout)
+	}
+
+	if err := pending.Resolve(Envelope{Id: "1", Kind: KindResponse}); !errors.Is(err, ErrNotWaiting) {
+		t.Fatalf("got %v for a request that timed out, want %v", err, ErrNotWaiting)
+	}
+}
+
+func TestPendingRequestsCancel(t *testing.T) {
+
+	pending := NewPendingRequests()
+	pending.Register("1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := pending.Wait(ctx, "1", time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPendingRequestsClose(t *testing.T) {
+
+	pending := NewPendingRequests()
+	pending.Register("waiting")
+	stream := pending.RegisterStream("stream")
+
+	pending.Close(http.StatusServiceUnavailable, errors.New("peer disconnected"))
+
+	response, err := pending.Wait(context.Background(), "waiting", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Kind != KindError || response.Status != http.StatusServiceUnavailable || response.Id != "waiting" {
+		t.Fatalf("got %+v, want a 503 error", response)
+	}
+
+	select {
+	case message := <-stream:
+		if message.Kind != KindError || message.Status != http.StatusServiceUnavailable {
+			t.Fatalf("got %+v, want a 503 error", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the stream was not closed")
+	}
+
+	// Requests registered after the peer is gone fail right away.
+	pending.Register("late")
+	response, err = pending.Wait(context.Background(), "late", time.Second)
+	if err != nil || response.Status != http.StatusServiceUnavailable || response.Error != "peer disconnected" {
+		t.Fatalf("got %+v and %v, want a 503 error", response, err)
+	}
+}
+
+func TestPendingRequestsSlowStream(t *testing.T) {
+
+	pending := NewPendingRequests()
+	stream := pending.RegisterStream("slow")
+	defer pending.Remove("slow")
+
+	for i := 0; i < StreamBuffer; i++ {
+		if err := pending.Resolve(Envelope{Id: "slow", Kind: KindStreamChunk}); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+	}
+
+	// The buffer is full: the chunk is refused right away instead of
+	// blocking the reader of the peer.
+	done := make(chan error, 1)
+	go func() {
+		done <- pending.Resolve(Envelope{Id: "slow", Kind: KindStreamChunk})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrReaderTooSlow) {
+			t.Fatalf("got %v, want %v", err, ErrReaderTooSlow)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Resolve blocked on a full stream")
+	}
+
+	if err := pending.Resolve(Envelope{Id: "slow", Kind: KindStreamChunk}); !errors.Is(err, ErrNotWaiting) {
+		t.Fatalf("got %v for a dropped stream, want %v", err, ErrNotWaiting)
+	}
+
+	// The reader gets the buffered chunks, then the error.
+	for i := 0; i < StreamBuffer; i++ {
+		if message := <-stream; message.Kind != KindStreamChunk {
+			t.Fatalf("message %d is %s, want a chunk", i, message.Kind)
+		}
+	}
+
+	select {
+	case message := <-stream:
+		if message.Kind != KindError || message.Status != http.StatusServiceUnavailable {
+			t.Fatalf("got %+v, want a 503 error", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the slow stream was not failed")
+	}
+}
+
+func TestPendingRequestsWaitUnregistered(t *testing.T) {
+
+	pending := NewPendingRequests()
+
+	if _, err := pending.Wait(context.Background(), "missing", time.Second); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -1,90 +1,66 @@
 package communication
 
 import (
-	"bytes"
+	"bufio"
 	"fmt"
 	"io"
 	"time"
 
-	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
 )
 
+var logger = logging.Logger("remote-containers/communication")
+
+// MessageProtocol is the libp2p protocol of the stream the envelopes
+// between two nodes are sent on.
+const MessageProtocol protocol.ID = "/stream/protocol"
+
 const (
 	ChunkSize    = 1024
 	Timeout      = 5 * time.Second
 	ReadDeadline = 10 * time.Second
 )
 
-type ResponseData struct {
-	Id   string
-	Data []byte
-	Err  error
-}
-
-func WriteData(stream network.Stream, data []byte) error {
-
-	chunks := splitIntoChunks(data, ChunkSize)
-
-	for _, chunk := range chunks {
-		_, err := stream.Write(chunk)
-		if err != nil {
-			return fmt.Errorf("error writing data to stream: %w", err)
-		}
-	}
+// WriteEnvelope writes the envelope as a single frame. Concurrent writers
+// to the same stream must be serialized by the caller.
+func WriteEnvelope(stream network.Stream, envelope Envelope) error {
 
-	_, err := stream.Write([]byte("END_OF_TRANSMISSION"))
+	err := WriteFrame(stream, envelope.toFrame())
 	if err != nil {
-		return fmt.Errorf("error writing end signal to stream: %w", err)
+		return fmt.Errorf("error writing data to stream: %w", err)
 	}
 
 	return nil
 }
 
-func splitIntoChunks(data []byte, chunkSize int) [][]byte {
-	var chunks [][]byte
-
-	for i := 0; i < len(data); i += chunkSize {
-		end := i + chunkSize
-		if end > len(data) {
-			end = len(data)
-		}
-		chunks = append(chunks, data[i:end])
-	}
-
-	return chunks
-}
+func HearStream(stream network.Stream, channel chan<- Envelope) {
 
-func HearStream(stream network.Stream, channelResponse chan<- ResponseData) {
-	var responseData ResponseData
+	reader := bufio.NewReaderSize(stream, ChunkSize)
 
-	var buf bytes.Buffer
-	buffer := make([]byte, ChunkSize)
 	for {
-		bytesRead, err := stream.Read(buffer)
-		if err != nil && err != io.EOF {
-			responseData.Err = fmt.Errorf("sendRequest: failed to read response data: %w", err)
-			fmt.Println("sendRequest: failed to read response data: %w", err)
+		frame, err := ReadFrame(reader)
+		if err == io.EOF {
+			logger.Debug("Stream closed by remote peer")
+			return
+		}
+		if err != nil {
+			logger.Debugf("Failed to read from stream: %s", err)
 			return
 		}
 
-		buf.Write(buffer[:bytesRead])
-
-		data := buf.Bytes()
-
-		endIndex := bytes.Index(data, []byte("END_OF_TRANSMISSION"))
-		if endIndex != -1 {
-			data = data[:endIndex]
-
-			responseData.Data = data
-			responseData.Id = uuid.New().String()
-
-			channelResponse <- responseData
+		if frame.Type != FrameData {
+			logger.Warnf("Ignoring frame of unknown type %d", frame.Type)
+			continue
+		}
 
-			buf.Reset()
-			if endIndex+18 < len(data) {
-				buf.Write(data[endIndex+18:])
-			}
+		envelope, err := envelopeFromFrame(frame)
+		if err != nil {
+			logger.Warnf("Ignoring malformed envelope: %s", err)
+			continue
 		}
+
+		channel <- envelope
 	}
 }
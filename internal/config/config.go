@@ -0,0 +1,281 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to the upper-cased flag name to get the
+// environment variable of every option, e.g. REMOTE_CONTAINERS_LOG_LEVEL.
+const EnvPrefix = "REMOTE_CONTAINERS_"
+
+// Config holds the options of a node. Each of them is taken, in order of
+// precedence, from its flag, its environment variable, the YAML config file
+// or its default value.
+type Config struct {
+	// Listen is the host:port the libp2p node listens on. Port 0 picks a
+	// free port.
+	Listen string
+	// Api is the host:port the HTTP API listens on.
+	Api string
+	// Peers are the multiaddrs of the nodes to connect to. Other nodes can
+	// connect to this one either way.
+	Peers []string
+	// Aliases are names the HTTP API accepts in place of peer IDs.
+	Aliases  map[string]peer.ID
+	Identity string
+	LogLevel string
+	// Mdns advertises the node on the local network and lists the nodes
+	// found there.
+	Mdns bool
+	// Cluster is the name shared by the nodes that AutoConnect connects to
+	// each other.
+	Cluster     string
+	AutoConnect bool
+	// AuthorizedPeers is the file listing the peers allowed to connect.
+	AuthorizedPeers string
+	// TrustOnFirstUse adds the unknown peers that connect to the
+	// authorized peers instead of rejecting them.
+	TrustOnFirstUse bool
+	// SwarmKey is the file of the pre-shared key of the private network
+	// the node joins. Without it the node is on the public network.
+	SwarmKey string
+}
+
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+func env(name string) []string {
+	return []string{EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))}
+}
+
+// Flags are the options shared by every command.
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Usage:   "YAML `file` with default values for the other options",
+			EnvVars: env("config"),
+		},
+		&cli.StringFlag{
+			Name:    "listen",
+			Usage:   "`host:port` the libp2p node listens on, port 0 picks a free one",
+			Value:   "0.0.0.0:0",
+			EnvVars: env("listen"),
+		},
+		&cli.StringFlag{
+			Name:    "api",
+			Usage:   "`host:port` the HTTP API listens on",
+			Value:   ":8080",
+			EnvVars: env("api"),
+		},
+		&cli.StringSliceFlag{
+			Name:    "peer",
+			Usage:   "`multiaddr` of a node to connect to, can be repeated",
+			EnvVars: env("peer"),
+		},
+		&cli.StringSliceFlag{
+			Name:    "alias",
+			Usage:   "`name=peerID` to address a peer by name in the HTTP API, can be repeated",
+			EnvVars: env("alias"),
+		},
+		&cli.BoolFlag{
+			Name:    "mdns",
+			Usage:   "find other nodes on the local network with mDNS",
+			Value:   true,
+			EnvVars: env("mdns"),
+		},
+		&cli.StringFlag{
+			Name:    "cluster",
+			Usage:   "`name` advertised on the local network, shared by the nodes to auto-connect to",
+			EnvVars: env("cluster"),
+		},
+		&cli.BoolFlag{
+			Name:    "auto-connect",
+			Usage:   "connect to the nodes found on the local network with the same cluster name",
+			EnvVars: env("auto-connect"),
+		},
+		&cli.StringFlag{
+			Name:    "authorized-peers",
+			Usage:   "YAML `file` listing the peers allowed to connect",
+			EnvVars: env("authorized-peers"),
+		},
+		&cli.BoolFlag{
+			Name:    "trust-on-first-use",
+			Usage:   "accept unknown peers and add them to the authorized peers as viewers, to pair nodes",
+			EnvVars: env("trust-on-first-use"),
+		},
+		&cli.StringFlag{
+			Name:    "swarm-key",
+			Usage:   "pre-shared key `file` of the private network to join, made with the keygen command",
+			EnvVars: env("swarm-key"),
+		},
+		&cli.StringFlag{
+			Name:    "identity",
+			Usage:   "key `file` that holds the node identity, created on first run",
+			EnvVars: env("identity"),
+		},
+		&cli.StringFlag{
+			Name:    "log-level",
+			Usage:   "one of " + strings.Join(LogLevels, ", "),
+			Value:   "info",
+			EnvVars: env("log-level"),
+		},
+	}
+}
+
+// Load reads the options of c, filling those given neither as a flag nor
+// as an environment variable from the config file, when there is one.
+func Load(c *cli.Context) (Config, error) {
+
+	if path := c.String("config"); path != "" {
+		err := applyFile(c, path)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	aliases, err := parseAliases(c.StringSlice("alias"))
+	if err != nil {
+		return Config{}, err
+	}
+
+	config := Config{
+		Listen:   c.String("listen"),
+		Api:      c.String("api"),
+		Peers:    c.StringSlice("peer"),
+		Aliases:  aliases,
+		Identity: c.String("identity"),
+		LogLevel: strings.ToLower(c.String("log-level")),
+
+		Mdns:        c.Bool("mdns"),
+		Cluster:     c.String("cluster"),
+		AutoConnect: c.Bool("auto-connect"),
+
+		AuthorizedPeers: c.String("authorized-peers"),
+		TrustOnFirstUse: c.Bool("trust-on-first-use"),
+		SwarmKey:        c.String("swarm-key"),
+	}
+
+	return config, config.Validate()
+}
+
+func parseAliases(values []string) (map[string]peer.ID, error) {
+
+	aliases := map[string]peer.ID{}
+
+	for _, value := range values {
+		name, id, ok := strings.Cut(value, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid alias %q, expected name=peerID", value)
+		}
+
+		peerID, err := peer.Decode(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %q: %w", value, err)
+		}
+
+		if _, taken := aliases[name]; taken {
+			return nil, fmt.Errorf("alias %q is given more than once", name)
+		}
+
+		aliases[name] = peerID
+	}
+
+	return aliases, nil
+}
+
+func (config Config) Validate() error {
+
+	if !contains(LogLevels, config.LogLevel) {
+		return fmt.Errorf("invalid log level %q, expected one of %s", config.LogLevel, strings.Join(LogLevels, ", "))
+	}
+
+	if config.AutoConnect && (!config.Mdns || config.Cluster == "") {
+		return errors.New("auto-connect needs mdns and a cluster name")
+	}
+
+	return nil
+}
+
+// applyFile sets the options found in the YAML file at path that were not
+// set otherwise. Its keys are the flag names, e.g.
+//
+//	listen: 0.0.0.0:4001
+//	log-level: debug
+func applyFile(c *cli.Context, path string) error {
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("config file %s does not exist", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var known []string
+	for _, flag := range Flags() {
+		known = append(known, flag.Names()...)
+	}
+
+	for _, name := range names {
+		if name == "config" || !contains(known, name) {
+			return fmt.Errorf("invalid config file %s: unknown option %q", path, name)
+		}
+
+		if c.IsSet(name) || values[name] == nil {
+			continue
+		}
+
+		// Lists, such as peers, set a flag that can be repeated once per
+		// item. Maps, such as aliases, are given as key=value items.
+		var items []interface{}
+		switch value := values[name].(type) {
+		case []interface{}:
+			items = value
+		case map[string]interface{}:
+			for key, item := range value {
+				items = append(items, fmt.Sprintf("%s=%v", key, item))
+			}
+		default:
+			items = []interface{}{value}
+		}
+
+		for _, item := range items {
+			err = c.Set(name, fmt.Sprint(item))
+			if err != nil {
+				return fmt.Errorf("invalid config file %s: %s: %w", path, name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// load runs a cli app with the flags of every command and the given
+// arguments, and returns the configuration it loaded.
+func load(t *testing.T, args ...string) (Config, error) {
+	t.Helper()
+
+	var config Config
+	var err error
+
+	app := &cli.App{
+		Flags: Flags(),
+		Action: func(c *cli.Context) error {
+			config, err = Load(c)
+			return nil
+		},
+	}
+
+	if runErr := app.Run(append([]string{"remote-containers"}, args...)); runErr != nil {
+		t.Fatal(runErr)
+	}
+
+	return config, err
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+
+	config, err := load(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Listen != "0.0.0.0:0" || config.LogLevel != "info" || len(config.Peers) != 0 || !config.Mdns {
+		t.Fatalf("unexpected defaults %+v", config)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+
+	path := writeFile(t, strings.Join([]string{
+		"listen: 0.0.0.0:4001",
+		"api: 127.0.0.1:9000",
+		"log-level: warn",
+		"cluster: from-file",
+		"mdns: false",
+	}, "\n"))
+
+	t.Setenv(EnvPrefix+"API", "127.0.0.1:9001")
+	t.Setenv(EnvPrefix+"LOG_LEVEL", "error")
+
+	config, err := load(t, "--config", path, "--log-level", "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct{ got, want string }{
+		"flag over env and file": {config.LogLevel, "debug"},
+		"env over file":          {config.Api, "127.0.0.1:9001"},
+		"file over default":      {config.Listen, "0.0.0.0:4001"},
+		"file without default":   {config.Cluster, "from-file"},
+	}
+
+	for name, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %q, want %q", name, test.got, test.want)
+		}
+	}
+
+	if config.Mdns {
+		t.Error("mdns is still enabled after the file disabled it")
+	}
+}
+
+func TestFileListsAndMaps(t *testing.T) {
+
+	path := writeFile(t, strings.Join([]string{
+		"peer:",
+		"  - /ip4/10.0.0.1/tcp/4001/p2p/12D3KooWGRUVh9ZMaSmv5r2mReqWyTcqnVCBNxLp3W5nBHGbkH7Y",
+		"  - /ip4/10.0.0.2/tcp/4001/p2p/12D3KooWGRUVh9ZMaSmv5r2mReqWyTcqnVCBNxLp3W5nBHGbkH7Y",
+		"alias:",
+		"  db: 12D3KooWGRUVh9ZMaSmv5r2mReqWyTcqnVCBNxLp3W5nBHGbkH7Y",
+	}, "\n"))
+
+	config, err := load(t, "--config", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"/ip4/10.0.0.1/tcp/4001/p2p/12D3KooWGRUVh9ZMaSmv5r2mReqWyTcqnVCBNxLp3W5nBHGbkH7Y",
+		"/ip4/10.0.0.2/tcp/4001/p2p/12D3KooWGRUVh9ZMaSmv5r2mReqWyTcqnVCBNxLp3W5nBHGbkH7Y",
+	}
+	if !reflect.DeepEqual(config.Peers, want) {
+		t.Fatalf("got peers %v, want %v", config.Peers, want)
+	}
+
+	if config.Aliases["db"].String() != "12D3KooWGRUVh9ZMaSmv5r2mReqWyTcqnVCBNxLp3W5nBHGbkH7Y" {
+		t.Fatalf("got aliases %v", config.Aliases)
+	}
+}
+
+func TestFileRejectsUnknownKeys(t *testing.T) {
+
+	path := writeFile(t, "listen: 0.0.0.0:4001\nlistne: 0.0.0.0:4002\n")
+
+	_, err := load(t, "--config", path)
+	if err == nil || !strings.Contains(err.Error(), `unknown option "listne"`) {
+		t.Fatalf("got %v, want an unknown option error", err)
+	}
+}
+
+func TestInvalidValues(t *testing.T) {
+
+	tests := map[string][]string{
+		"log level":    {"--log-level", "verbose"},
+		"alias":        {"--alias", "db"},
+		"auto-connect": {"--auto-connect", "--cluster", "lab", "--mdns=false"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := load(t, args...); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
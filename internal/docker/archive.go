@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+)
+
+// CopyFromContainer writes a tar archive of path inside the container to w
+// as the daemon produces it.
+func (myDocker DockerClient) CopyFromContainer(ctx context.Context, containerID string, path string, w io.Writer) error {
+
+	if path == "" {
+		return errdefs.InvalidParameter(errors.New("path is required"))
+	}
+
+	archive, _, err := myDocker.Client.CopyFromContainer(ctx, containerID, path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	_, err = io.Copy(w, archive)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+type CopyToContainerOptions struct {
+	// NoOverwriteDirNonDir refuses to replace a directory with a file or a
+	// file with a directory.
+	NoOverwriteDirNonDir bool
+	// CopyUIDGID keeps the owner of the files from the archive.
+	CopyUIDGID bool
+}
+
+// CopyToContainer extracts the tar archive read from content into the
+// directory path inside the container.
+func (myDocker DockerClient) CopyToContainer(ctx context.Context, containerID string, path string, content io.Reader, options CopyToContainerOptions) error {
+
+	if path == "" {
+		return errdefs.InvalidParameter(errors.New("path is required"))
+	}
+
+	return myDocker.Client.CopyToContainer(ctx, containerID, path, content, types.CopyToContainerOptions{
+		AllowOverwriteDirWithFile: !options.NoOverwriteDirNonDir,
+		CopyUIDGID:                options.CopyUIDGID,
+	})
+}
@@ -0,0 +1,261 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
+)
+
+type CreateRequest struct {
+	Image      string   `json:"image" binding:"required"`
+	Name       string   `json:"name"`
+	Cmd        []string `json:"cmd"`
+	Entrypoint []string `json:"entrypoint"`
+	// Env entries use the KEY=value form.
+	Env        []string          `json:"env"`
+	WorkingDir string            `json:"workingDir"`
+	User       string            `json:"user"`
+	Labels     map[string]string `json:"labels"`
+	Ports      []PortBinding     `json:"ports"`
+	Mounts     []Mount           `json:"mounts"`
+	// Volumes lists container paths that get an anonymous volume.
+	Volumes       []string       `json:"volumes"`
+	RestartPolicy *RestartPolicy `json:"restartPolicy"`
+	// Memory is the memory limit in bytes.
+	Memory int64 `json:"memory"`
+	// CPUs is the number of CPUs the container may use, e.g. 1.5.
+	CPUs           float64  `json:"cpus"`
+	Network        string   `json:"network"`
+	NetworkAliases []string `json:"networkAliases"`
+	// Pull tells whether to pull the image before creating the container.
+	// It defaults to never.
+	Pull string        `json:"pull" enums:"missing,always,never"`
+	Auth *RegistryAuth `json:"auth"`
+}
+
+type PortBinding struct {
+	// ContainerPort is a port number with an optional protocol, e.g. "80/udp".
+	ContainerPort string `json:"containerPort" binding:"required"`
+	HostPort      string `json:"hostPort"`
+	HostIP        string `json:"hostIp"`
+}
+
+type Mount struct {
+	// Type is one of bind, volume or tmpfs. It defaults to bind when the
+	// source is an absolute path and to volume otherwise.
+	Type     string `json:"type" enums:"bind,volume,tmpfs"`
+	Source   string `json:"source"`
+	Target   string `json:"target" binding:"required"`
+	ReadOnly bool   `json:"readOnly"`
+}
+
+type RestartPolicy struct {
+	Name              string `json:"name" enums:"no,always,on-failure,unless-stopped"`
+	MaximumRetryCount int    `json:"maximumRetryCount"`
+}
+
+// Validate checks the request before it reaches the daemon. The returned
+// error is an invalid parameter error, so it maps to a 400.
+func (request CreateRequest) Validate() error {
+	var problems []string
+
+	if request.Image == "" {
+		problems = append(problems, "image is required")
+	}
+
+	for _, env := range request.Env {
+		if !strings.Contains(env, "=") || strings.HasPrefix(env, "=") {
+			problems = append(problems, fmt.Sprintf("env %q must have the KEY=value form", env))
+		}
+	}
+
+	for _, port := range request.Ports {
+		proto, number := nat.SplitProtoPort(port.ContainerPort)
+		if _, err := nat.NewPort(proto, number); err != nil || number == "" {
+			problems = append(problems, fmt.Sprintf("invalid container port %q", port.ContainerPort))
+		}
+		if port.HostPort != "" {
+			if _, _, err := nat.ParsePortRange(port.HostPort); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid host port %q", port.HostPort))
+			}
+		}
+	}
+
+	for _, m := range request.Mounts {
+		if !path.IsAbs(m.Target) {
+			problems = append(problems, fmt.Sprintf("mount target %q must be an absolute path", m.Target))
+		}
+		switch m.mountType() {
+		case mount.TypeBind:
+			if !path.IsAbs(m.Source) {
+				problems = append(problems, fmt.Sprintf("bind mount source %q must be an absolute path", m.Source))
+			}
+		case mount.TypeVolume, mount.TypeTmpfs:
+		default:
+			problems = append(problems, fmt.Sprintf("unsupported mount type %q", m.Type))
+		}
+	}
+
+	for _, volume := range request.Volumes {
+		if !path.IsAbs(volume) {
+			problems = append(problems, fmt.Sprintf("volume %q must be an absolute path", volume))
+		}
+	}
+
+	if request.RestartPolicy != nil {
+		if err := container.ValidateRestartPolicy(request.RestartPolicy.toDocker()); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if request.Memory < 0 {
+		problems = append(problems, "memory cannot be negative")
+	}
+
+	if request.CPUs < 0 {
+		problems = append(problems, "cpus cannot be negative")
+	}
+
+	switch request.Pull {
+	case "", PullMissing, PullAlways, PullNever:
+	default:
+		problems = append(problems, fmt.Sprintf("unknown pull policy %q", request.Pull))
+	}
+
+	if len(request.NetworkAliases) > 0 && request.Network == "" {
+		problems = append(problems, "networkAliases require a network")
+	}
+
+	if len(problems) > 0 {
+		return errdefs.InvalidParameter(errors.New(strings.Join(problems, "; ")))
+	}
+
+	return nil
+}
+
+func (m Mount) mountType() mount.Type {
+	if m.Type != "" {
+		return mount.Type(m.Type)
+	}
+
+	if path.IsAbs(m.Source) {
+		return mount.TypeBind
+	}
+
+	return mount.TypeVolume
+}
+
+func (policy RestartPolicy) toDocker() container.RestartPolicy {
+	return container.RestartPolicy{
+		Name:              container.RestartPolicyMode(policy.Name),
+		MaximumRetryCount: policy.MaximumRetryCount,
+	}
+}
+
+func (request CreateRequest) config() *container.Config {
+	config := &container.Config{
+		Image:      request.Image,
+		Cmd:        request.Cmd,
+		Entrypoint: request.Entrypoint,
+		Env:        request.Env,
+		WorkingDir: request.WorkingDir,
+		User:       request.User,
+		Labels:     request.Labels,
+	}
+
+	if len(request.Ports) > 0 {
+		config.ExposedPorts = nat.PortSet{}
+		for _, port := range request.Ports {
+			config.ExposedPorts[port.natPort()] = struct{}{}
+		}
+	}
+
+	if len(request.Volumes) > 0 {
+		config.Volumes = map[string]struct{}{}
+		for _, volume := range request.Volumes {
+			config.Volumes[volume] = struct{}{}
+		}
+	}
+
+	return config
+}
+
+func (request CreateRequest) hostConfig() *container.HostConfig {
+	hostConfig := &container.HostConfig{}
+
+	if len(request.Ports) > 0 {
+		hostConfig.PortBindings = nat.PortMap{}
+		for _, port := range request.Ports {
+			hostConfig.PortBindings[port.natPort()] = append(hostConfig.PortBindings[port.natPort()], nat.PortBinding{
+				HostIP:   port.HostIP,
+				HostPort: port.HostPort,
+			})
+		}
+	}
+
+	for _, m := range request.Mounts {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     m.mountType(),
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+
+	if request.RestartPolicy != nil {
+		hostConfig.RestartPolicy = request.RestartPolicy.toDocker()
+	}
+
+	hostConfig.Memory = request.Memory
+	hostConfig.NanoCPUs = int64(request.CPUs * 1e9)
+
+	if request.Network != "" {
+		hostConfig.NetworkMode = container.NetworkMode(request.Network)
+	}
+
+	return hostConfig
+}
+
+func (request CreateRequest) networkingConfig() *network.NetworkingConfig {
+	if request.Network == "" {
+		return nil
+	}
+
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			request.Network: {Aliases: request.NetworkAliases},
+		},
+	}
+}
+
+func (port PortBinding) natPort() nat.Port {
+	proto, number := nat.SplitProtoPort(port.ContainerPort)
+	return nat.Port(number + "/" + proto)
+}
+
+func (myDocker *DockerClient) CreateContainer(request CreateRequest) (*container.CreateResponse, error) {
+
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := myDocker.ensureImage(context.Background(), request.Image, request.Pull, request.Auth); err != nil {
+		return nil, err
+	}
+
+	resp, err := myDocker.Client.ContainerCreate(context.Background(), request.config(), request.hostConfig(), request.networkingConfig(), nil, request.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,167 @@
+package docker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestCreateRequestValidate(t *testing.T) {
+
+	tests := map[string]struct {
+		request CreateRequest
+		// problem is part of the error, empty when the request is valid.
+		problem string
+	}{
+		"minimal":             {CreateRequest{Image: "nginx"}, ""},
+		"missing image":       {CreateRequest{}, "image is required"},
+		"env":                 {CreateRequest{Image: "nginx", Env: []string{"A=1", "B="}}, ""},
+		"env without value":   {CreateRequest{Image: "nginx", Env: []string{"A"}}, `env "A" must have the KEY=value form`},
+		"env without key":     {CreateRequest{Image: "nginx", Env: []string{"=1"}}, `env "=1" must have the KEY=value form`},
+		"ports":               {CreateRequest{Image: "nginx", Ports: []PortBinding{{ContainerPort: "80", HostPort: "8080"}, {ContainerPort: "53/udp", HostPort: "5300-5301"}}}, ""},
+		"container port":      {CreateRequest{Image: "nginx", Ports: []PortBinding{{ContainerPort: "http"}}}, `invalid container port "http"`},
+		"empty port":          {CreateRequest{Image: "nginx", Ports: []PortBinding{{ContainerPort: "/tcp"}}}, `invalid container port "/tcp"`},
+		"host port":           {CreateRequest{Image: "nginx", Ports: []PortBinding{{ContainerPort: "80", HostPort: "99999"}}}, `invalid host port "99999"`},
+		"mounts":              {CreateRequest{Image: "nginx", Mounts: []Mount{{Source: "/srv", Target: "/data"}, {Source: "cache", Target: "/cache"}, {Type: "tmpfs", Target: "/tmp"}}}, ""},
+		"relative target":     {CreateRequest{Image: "nginx", Mounts: []Mount{{Source: "cache", Target: "data"}}}, `mount target "data" must be an absolute path`},
+		"relative bind":       {CreateRequest{Image: "nginx", Mounts: []Mount{{Type: "bind", Source: "srv", Target: "/data"}}}, `bind mount source "srv" must be an absolute path`},
+		"mount type":          {CreateRequest{Image: "nginx", Mounts: []Mount{{Type: "npipe", Source: "/srv", Target: "/data"}}}, `unsupported mount type "npipe"`},
+		"relative volume":     {CreateRequest{Image: "nginx", Volumes: []string{"data"}}, `volume "data" must be an absolute path`},
+		"restart policy":      {CreateRequest{Image: "nginx", RestartPolicy: &RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}}, ""},
+		"unknown restart":     {CreateRequest{Image: "nginx", RestartPolicy: &RestartPolicy{Name: "sometimes"}}, "sometimes"},
+		"retries with always": {CreateRequest{Image: "nginx", RestartPolicy: &RestartPolicy{Name: "always", MaximumRetryCount: 3}}, "maximum retry count"},
+		"negative retries":    {CreateRequest{Image: "nginx", RestartPolicy: &RestartPolicy{Name: "on-failure", MaximumRetryCount: -1}}, "maximum retry count"},
+		"negative memory":     {CreateRequest{Image: "nginx", Memory: -1}, "memory cannot be negative"},
+		"negative cpus":       {CreateRequest{Image: "nginx", CPUs: -0.5}, "cpus cannot be negative"},
+		"pull policy":         {CreateRequest{Image: "nginx", Pull: "sometimes"}, `unknown pull policy "sometimes"`},
+		"aliases":             {CreateRequest{Image: "nginx", NetworkAliases: []string{"web"}}, "networkAliases require a network"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			err := test.request.Validate()
+
+			if test.problem == "" {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Fatalf("got %v, want an error about %q", err, test.problem)
+			}
+			if !errdefs.IsInvalidParameter(err) {
+				t.Fatalf("got %T, want an invalid parameter error", err)
+			}
+		})
+	}
+}
+
+func TestCreateRequestValidateReportsEveryProblem(t *testing.T) {
+
+	err := CreateRequest{Env: []string{"A"}, Memory: -1}.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, problem := range []string{"image is required", `env "A"`, "memory cannot be negative"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("%q is missing from %q", problem, err)
+		}
+	}
+}
+
+func TestCreateRequestConfig(t *testing.T) {
+
+	request := CreateRequest{
+		Image: "nginx",
+		Env:   []string{"A=1"},
+		Ports: []PortBinding{
+			{ContainerPort: "80", HostPort: "8080"},
+			{ContainerPort: "80/tcp", HostPort: "8081", HostIP: "127.0.0.1"},
+			{ContainerPort: "53/udp", HostPort: "5353"},
+		},
+		Mounts: []Mount{
+			{Source: "/srv/www", Target: "/usr/share/nginx/html", ReadOnly: true},
+			{Source: "cache", Target: "/cache"},
+			{Type: "tmpfs", Target: "/tmp"},
+		},
+		Volumes:       []string{"/data"},
+		RestartPolicy: &RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+		Memory:        512 << 20,
+		CPUs:          1.5,
+		Network:       "lab",
+	}
+
+	config := request.config()
+
+	wantExposed := nat.PortSet{"80/tcp": {}, "53/udp": {}}
+	if !reflect.DeepEqual(config.ExposedPorts, wantExposed) {
+		t.Errorf("got exposed ports %v, want %v", config.ExposedPorts, wantExposed)
+	}
+	if !reflect.DeepEqual(config.Volumes, map[string]struct{}{"/data": {}}) {
+		t.Errorf("got volumes %v", config.Volumes)
+	}
+	if config.Image != "nginx" || !reflect.DeepEqual(config.Env, request.Env) {
+		t.Errorf("got image %q and env %v", config.Image, config.Env)
+	}
+
+	hostConfig := request.hostConfig()
+
+	wantBindings := nat.PortMap{
+		"80/tcp": {{HostPort: "8080"}, {HostIP: "127.0.0.1", HostPort: "8081"}},
+		"53/udp": {{HostPort: "5353"}},
+	}
+	if !reflect.DeepEqual(hostConfig.PortBindings, wantBindings) {
+		t.Errorf("got port bindings %v, want %v", hostConfig.PortBindings, wantBindings)
+	}
+
+	wantMounts := []mount.Mount{
+		{Type: mount.TypeBind, Source: "/srv/www", Target: "/usr/share/nginx/html", ReadOnly: true},
+		{Type: mount.TypeVolume, Source: "cache", Target: "/cache"},
+		{Type: mount.TypeTmpfs, Target: "/tmp"},
+	}
+	if !reflect.DeepEqual(hostConfig.Mounts, wantMounts) {
+		t.Errorf("got mounts %+v, want %+v", hostConfig.Mounts, wantMounts)
+	}
+
+	if hostConfig.NanoCPUs != 1_500_000_000 {
+		t.Errorf("got %d NanoCPUs, want 1500000000", hostConfig.NanoCPUs)
+	}
+	if hostConfig.Memory != 512<<20 {
+		t.Errorf("got a memory limit of %d", hostConfig.Memory)
+	}
+
+	wantPolicy := container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 3}
+	if hostConfig.RestartPolicy != wantPolicy {
+		t.Errorf("got restart policy %+v, want %+v", hostConfig.RestartPolicy, wantPolicy)
+	}
+	if hostConfig.NetworkMode != "lab" {
+		t.Errorf("got network mode %q", hostConfig.NetworkMode)
+	}
+}
+
+func TestCreateRequestDefaults(t *testing.T) {
+
+	request := CreateRequest{Image: "nginx"}
+
+	config := request.config()
+	if config.ExposedPorts != nil || config.Volumes != nil {
+		t.Errorf("got exposed ports %v and volumes %v, want none", config.ExposedPorts, config.Volumes)
+	}
+
+	hostConfig := request.hostConfig()
+	if hostConfig.PortBindings != nil || hostConfig.Mounts != nil || hostConfig.NanoCPUs != 0 || hostConfig.NetworkMode != "" {
+		t.Errorf("got %+v, want an empty host config", hostConfig)
+	}
+	if request.networkingConfig() != nil {
+		t.Error("got a networking config without a network")
+	}
+}
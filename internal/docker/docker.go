@@ -2,13 +2,15 @@ package docker
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	logging "github.com/ipfs/go-log/v2"
 )
 
+var logger = logging.Logger("remote-containers/docker")
+
 type DockerClient struct {
 	Client *client.Client
 }
@@ -18,7 +20,7 @@ func New() DockerClient {
 	client, err := client.NewClientWithOpts(client.FromEnv)
 
 	if err != nil {
-		fmt.Println("docker client error", err.Error())
+		logger.Errorf("Docker client error: %s", err)
 	}
 
 	return DockerClient{
@@ -27,54 +29,26 @@ func New() DockerClient {
 
 }
 
-func (myDocker *DockerClient) ListContainers(options container.ListOptions) []types.Container {
+func (myDocker *DockerClient) ListContainers(options container.ListOptions) ([]types.Container, error) {
 
 	containers, err := myDocker.Client.ContainerList(context.Background(), options)
 	if err != nil {
-		fmt.Println(err.Error())
-		return nil
+		return nil, err
 	}
 
-	return containers
-}
-
-type CreateRequest struct {
-	Image string   `json:"image" binding:"required"`
-	Name  string   `json:"name"`
-	Cmd   []string `json:"cmd"`
+	return containers, nil
 }
 
-func (myDocker *DockerClient) CreateContainer(request CreateRequest) *container.CreateResponse {
-
-	resp, err := myDocker.Client.ContainerCreate(context.Background(), &container.Config{
-		Image: request.Image,
-		Cmd:   request.Cmd,
-	}, nil, nil, nil, request.Name)
-
-	if err != nil {
-		fmt.Println(err.Error())
-		return nil
-	}
-
-	return &resp
-}
-
-func (myDocker DockerClient) InspectContainer(containerID string) *types.ContainerJSON {
+func (myDocker DockerClient) InspectContainer(containerID string) (*types.ContainerJSON, error) {
 	containerJSON, err := myDocker.Client.ContainerInspect(context.Background(), containerID)
 	if err != nil {
-		fmt.Println(err.Error())
-		return nil
+		return nil, err
 	}
 
-	return &containerJSON
+	return &containerJSON, nil
 }
 
-func (myDocker DockerClient) DeleteContainer(containerID string) {
-
-	err := myDocker.Client.ContainerRemove(context.Background(), containerID, container.RemoveOptions{})
-	if err != nil {
-		fmt.Println(err.Error())
-		return
-	}
+func (myDocker DockerClient) DeleteContainer(containerID string, options container.RemoveOptions) error {
 
+	return myDocker.Client.ContainerRemove(context.Background(), containerID, options)
 }
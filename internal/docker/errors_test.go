@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+)
+
+func TestStatusCode(t *testing.T) {
+
+	cause := errors.New("cause")
+
+	tests := map[string]struct {
+		err    error
+		status int
+	}{
+		"nil":               {nil, http.StatusOK},
+		"not found":         {errdefs.NotFound(cause), http.StatusNotFound},
+		"conflict":          {errdefs.Conflict(cause), http.StatusConflict},
+		"invalid parameter": {errdefs.InvalidParameter(cause), http.StatusBadRequest},
+		"unauthorized":      {errdefs.Unauthorized(cause), http.StatusUnauthorized},
+		"forbidden":         {errdefs.Forbidden(cause), http.StatusForbidden},
+		"not implemented":   {errdefs.NotImplemented(cause), http.StatusNotImplemented},
+		"unavailable":       {errdefs.Unavailable(cause), http.StatusServiceUnavailable},
+		"deadline":          {errdefs.Deadline(cause), http.StatusGatewayTimeout},
+		"context deadline":  {context.DeadlineExceeded, http.StatusGatewayTimeout},
+		"system":            {errdefs.System(cause), http.StatusInternalServerError},
+		"plain":             {cause, http.StatusInternalServerError},
+		"wrapped":           {fmt.Errorf("removing container: %w", errdefs.NotFound(cause)), http.StatusNotFound},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if status := StatusCode(test.err); status != test.status {
+				t.Fatalf("got %d, want %d", status, test.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/errdefs"
+)
+
+// EventsMinBackoff and EventsMaxBackoff bound the wait before an events
+// subscription that dropped is made again.
+const (
+	EventsMinBackoff = time.Second
+	EventsMaxBackoff = 30 * time.Second
+)
+
+// WatchEvents hands every Docker event matching the filters to each until
+// ctx is cancelled or each fails. When the daemon connection drops, it
+// subscribes again from the time of the last event, so no event is lost.
+func (myDocker DockerClient) WatchEvents(ctx context.Context, eventFilters filters.Args, since string, each func(events.Message) error) error {
+
+	backoff := EventsMinBackoff
+	var last *EventPosition
+
+	for {
+		messages, errs := myDocker.Client.Events(ctx, types.EventsOptions{
+			Since:   since,
+			Filters: eventFilters,
+		})
+
+		var err error
+	read:
+		for {
+			select {
+			case message := <-messages:
+				if last != nil && last.Seen(message) {
+					continue
+				}
+
+				backoff = EventsMinBackoff
+				position := PositionOf(message)
+				last = &position
+				since = position.Since()
+				if err := each(message); err != nil {
+					return err
+				}
+			case err = <-errs:
+				break read
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if errdefs.IsInvalidParameter(err) {
+			return err
+		}
+
+		logger.Warnf("Docker events subscription dropped, retrying in %s: %s", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, EventsMaxBackoff)
+	}
+}
+
+// EventPosition is where an event stream got to: the time of its last
+// event and the object that event was about. Docker replays the events at
+// the since time too, so a stream resumed from a position skips those it
+// already sent.
+type EventPosition struct {
+	// Time is in nanoseconds since the Unix epoch.
+	Time  int64
+	Actor string
+}
+
+func PositionOf(message events.Message) EventPosition {
+	return EventPosition{Time: eventTime(message), Actor: message.Actor.ID}
+}
+
+func eventTime(message events.Message) int64 {
+	if message.TimeNano != 0 {
+		return message.TimeNano
+	}
+	return message.Time * int64(time.Second)
+}
+
+// ParseEventPosition reads a position written by String.
+func ParseEventPosition(value string) (EventPosition, error) {
+
+	cursor, actor, _ := strings.Cut(value, "/")
+	seconds, nanos, _ := strings.Cut(cursor, ".")
+
+	s, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return EventPosition{}, fmt.Errorf("invalid event position %q", value)
+	}
+
+	var n int64
+	if nanos != "" {
+		n, err = strconv.ParseInt(nanos, 10, 64)
+		if err != nil || len(nanos) != 9 {
+			return EventPosition{}, fmt.Errorf("invalid event position %q", value)
+		}
+	}
+
+	return EventPosition{Time: s*int64(time.Second) + n, Actor: actor}, nil
+}
+
+// String is the since cursor followed by the actor, e.g.
+// 1700000000.000000001/3f4e..., used as the id of Server-Sent Events.
+func (p EventPosition) String() string {
+	return p.Since() + "/" + p.Actor
+}
+
+// Since formats the time of the position the way the since and until
+// options of the events API expect it.
+func (p EventPosition) Since() string {
+	return fmt.Sprintf("%d.%09d", p.Time/int64(time.Second), p.Time%int64(time.Second))
+}
+
+// Seen reports whether the event comes at or before the position: earlier,
+// or at the same time about the same object.
+func (p EventPosition) Seen(message events.Message) bool {
+	at := eventTime(message)
+	return at < p.Time || (at == p.Time && message.Actor.ID == p.Actor)
+}
@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+func TestEventPositionRoundTrip(t *testing.T) {
+
+	message := events.Message{TimeNano: 1700000000000000001, Actor: events.Actor{ID: "web"}}
+	position := PositionOf(message)
+
+	if position.String() != "1700000000.000000001/web" {
+		t.Fatalf("got %q", position.String())
+	}
+
+	parsed, err := ParseEventPosition(position.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != position {
+		t.Fatalf("got %+v, want %+v", parsed, position)
+	}
+
+	for _, invalid := range []string{"", "abc", "17.12/web", "17.abcdefghi"} {
+		if _, err := ParseEventPosition(invalid); err == nil {
+			t.Fatalf("%q was accepted", invalid)
+		}
+	}
+}
+
+func TestEventPositionSeen(t *testing.T) {
+
+	position := EventPosition{Time: 2000, Actor: "web"}
+
+	tests := map[string]struct {
+		message events.Message
+		seen    bool
+	}{
+		"earlier":                {events.Message{TimeNano: 1999, Actor: events.Actor{ID: "db"}}, true},
+		"same event":             {events.Message{TimeNano: 2000, Actor: events.Actor{ID: "web"}}, true},
+		"same time, other actor": {events.Message{TimeNano: 2000, Actor: events.Actor{ID: "db"}}, false},
+		"later":                  {events.Message{TimeNano: 2001, Actor: events.Actor{ID: "web"}}, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if seen := position.Seen(test.message); seen != test.seen {
+				t.Fatalf("got %v, want %v", seen, test.seen)
+			}
+		})
+	}
+}
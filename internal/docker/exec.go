@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// StartExec creates an exec instance in the container and attaches to its
+// standard streams. The caller must close the returned connection.
+func (myDocker DockerClient) StartExec(ctx context.Context, containerID string, config types.ExecConfig) (string, types.HijackedResponse, error) {
+
+	config.AttachStdin = true
+	config.AttachStdout = true
+	config.AttachStderr = true
+
+	exec, err := myDocker.Client.ContainerExecCreate(ctx, containerID, config)
+	if err != nil {
+		return "", types.HijackedResponse{}, err
+	}
+
+	attach, err := myDocker.Client.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{
+		Tty:         config.Tty,
+		ConsoleSize: config.ConsoleSize,
+	})
+	if err != nil {
+		return "", types.HijackedResponse{}, err
+	}
+
+	return exec.ID, attach, nil
+}
+
+func (myDocker DockerClient) ResizeExec(ctx context.Context, execID string, height uint, width uint) error {
+
+	return myDocker.Client.ContainerExecResize(ctx, execID, container.ResizeOptions{
+		Height: height,
+		Width:  width,
+	})
+}
+
+// ExecExitCode waits for the exec process to finish and returns its exit
+// code. The output of a process can close shortly before Docker records
+// that it exited, so the state is polled until then.
+func (myDocker DockerClient) ExecExitCode(ctx context.Context, execID string) (int, error) {
+
+	for {
+		inspect, err := myDocker.Client.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return 0, err
+		}
+
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+)
+
+// Pull policies accepted when creating a container.
+const (
+	PullMissing = "missing"
+	PullAlways  = "always"
+	PullNever   = "never"
+)
+
+// PullProgress is one of the JSON messages the daemon streams while pulling.
+type PullProgress struct {
+	Status         string `json:"status,omitempty"`
+	ID             string `json:"id,omitempty"`
+	Progress       string `json:"progress,omitempty"`
+	ProgressDetail struct {
+		Current int64 `json:"current,omitempty"`
+		Total   int64 `json:"total,omitempty"`
+	} `json:"progressDetail"`
+	Error       string `json:"error,omitempty"`
+	ErrorDetail *struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	} `json:"errorDetail,omitempty"`
+}
+
+type PullRequest struct {
+	Image string `json:"image" binding:"required"`
+	// Platform selects the image variant, e.g. linux/arm64.
+	Platform string        `json:"platform"`
+	Auth     *RegistryAuth `json:"auth"`
+}
+
+// RegistryAuth holds the credentials for a private registry. They are only
+// forwarded to the Docker daemon of the remote node and must never be logged.
+type RegistryAuth struct {
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	ServerAddress string `json:"serverAddress"`
+	IdentityToken string `json:"identityToken"`
+	RegistryToken string `json:"registryToken"`
+}
+
+// String keeps the credentials out of any accidental log line.
+func (auth RegistryAuth) String() string {
+	return fmt.Sprintf("RegistryAuth{Username: %q, ServerAddress: %q}", auth.Username, auth.ServerAddress)
+}
+
+func (auth RegistryAuth) GoString() string {
+	return auth.String()
+}
+
+func (auth *RegistryAuth) encode() (string, error) {
+	if auth == nil {
+		return "", nil
+	}
+
+	return registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      auth.Username,
+		Password:      auth.Password,
+		ServerAddress: auth.ServerAddress,
+		IdentityToken: auth.IdentityToken,
+		RegistryToken: auth.RegistryToken,
+	})
+}
+
+// PullImage pulls the image and hands every progress message to progress.
+// A failed pull is reported both as a progress message and as the returned
+// error.
+func (myDocker DockerClient) PullImage(ctx context.Context, request PullRequest, progress func(PullProgress) error) error {
+
+	if request.Image == "" {
+		return errdefs.InvalidParameter(errors.New("image is required"))
+	}
+
+	registryAuth, err := request.Auth.encode()
+	if err != nil {
+		return errdefs.InvalidParameter(fmt.Errorf("invalid registry credentials: %w", err))
+	}
+
+	reader, err := myDocker.Client.ImagePull(ctx, request.Image, types.ImagePullOptions{
+		RegistryAuth: registryAuth,
+		Platform:     request.Platform,
+	})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	decoder := json.NewDecoder(reader)
+	for {
+		var message PullProgress
+		err := decoder.Decode(&message)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := progress(message); err != nil {
+			return err
+		}
+
+		if message.Error != "" {
+			return pullError(message)
+		}
+	}
+}
+
+// pullError classifies the error reported in the pull progress, which the
+// daemon only sends as text with an HTTP-like code.
+func pullError(message PullProgress) error {
+	err := errors.New(message.Error)
+
+	code := 0
+	if message.ErrorDetail != nil {
+		code = message.ErrorDetail.Code
+	}
+
+	switch code {
+	case 401:
+		return errdefs.Unauthorized(err)
+	case 404:
+		return errdefs.NotFound(err)
+	default:
+		return errdefs.System(err)
+	}
+}
+
+// ensureImage pulls the image of a new container according to the pull
+// policy. An empty policy means never, which leaves a missing image to be
+// reported by the create call.
+func (myDocker DockerClient) ensureImage(ctx context.Context, image string, policy string, auth *RegistryAuth) error {
+
+	switch policy {
+	case "", PullNever:
+		return nil
+	case PullMissing:
+		_, _, err := myDocker.Client.ImageInspectWithRaw(ctx, image)
+		if err == nil {
+			return nil
+		}
+		if !errdefs.IsNotFound(err) {
+			return err
+		}
+	case PullAlways:
+	default:
+		return errdefs.InvalidParameter(fmt.Errorf("unknown pull policy %q", policy))
+	}
+
+	return myDocker.PullImage(ctx, PullRequest{Image: image, Auth: auth}, func(PullProgress) error {
+		return nil
+	})
+}
+
+func (myDocker DockerClient) ListImages(options types.ImageListOptions) ([]image.Summary, error) {
+
+	return myDocker.Client.ImageList(context.Background(), options)
+}
+
+func (myDocker DockerClient) InspectImage(imageID string) (*types.ImageInspect, error) {
+
+	inspect, _, err := myDocker.Client.ImageInspectWithRaw(context.Background(), imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inspect, nil
+}
+
+func (myDocker DockerClient) RemoveImage(imageID string, options types.ImageRemoveOptions) ([]image.DeleteResponse, error) {
+
+	return myDocker.Client.ImageRemove(context.Background(), imageID, options)
+}
+
+func (myDocker DockerClient) TagImage(source string, target string) error {
+
+	return myDocker.Client.ImageTag(context.Background(), source, target)
+}
+
+// PruneImages removes dangling images, or every image not used by a
+// container when all is set.
+func (myDocker DockerClient) PruneImages(all bool, pruneFilters filters.Args) (types.ImagesPruneReport, error) {
+
+	if all {
+		pruneFilters.Add("dangling", "false")
+	}
+
+	return myDocker.Client.ImagesPrune(context.Background(), pruneFilters)
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func (myDocker DockerClient) StartContainer(containerID string) error {
+
+	return myDocker.Client.ContainerStart(context.Background(), containerID, container.StartOptions{})
+}
+
+// StopContainer stops the container, waiting timeout seconds before killing
+// it. A nil timeout uses the daemon default.
+func (myDocker DockerClient) StopContainer(containerID string, timeout *int) error {
+
+	return myDocker.Client.ContainerStop(context.Background(), containerID, container.StopOptions{Timeout: timeout})
+}
+
+func (myDocker DockerClient) RestartContainer(containerID string, timeout *int) error {
+
+	return myDocker.Client.ContainerRestart(context.Background(), containerID, container.StopOptions{Timeout: timeout})
+}
+
+func (myDocker DockerClient) KillContainer(containerID string, signal string) error {
+
+	return myDocker.Client.ContainerKill(context.Background(), containerID, signal)
+}
+
+func (myDocker DockerClient) PauseContainer(containerID string) error {
+
+	return myDocker.Client.ContainerPause(context.Background(), containerID)
+}
+
+func (myDocker DockerClient) UnpauseContainer(containerID string) error {
+
+	return myDocker.Client.ContainerUnpause(context.Background(), containerID)
+}
+
+func (myDocker DockerClient) ContainerState(containerID string) (*types.ContainerState, error) {
+
+	containerJSON, err := myDocker.InspectContainer(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return containerJSON.State, nil
+}
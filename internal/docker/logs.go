@@ -0,0 +1,38 @@
+package docker
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ContainerLogs copies the logs of the container to stdout and stderr until
+// they end or ctx is cancelled. Containers without a TTY get their
+// multiplexed log stream split back into the two outputs.
+func (myDocker DockerClient) ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions, stdout io.Writer, stderr io.Writer) error {
+
+	containerJSON, err := myDocker.Client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
+
+	logs, err := myDocker.Client.ContainerLogs(ctx, containerID, options)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	if containerJSON.Config != nil && containerJSON.Config.Tty {
+		_, err = io.Copy(stdout, logs)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, logs)
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+)
+
+type NetworkCreateRequest struct {
+	Name       string            `json:"name" binding:"required"`
+	Driver     string            `json:"driver"`
+	Internal   bool              `json:"internal"`
+	Attachable bool              `json:"attachable"`
+	EnableIPv6 bool              `json:"enableIPv6"`
+	Labels     map[string]string `json:"labels"`
+	Options    map[string]string `json:"options"`
+	// Subnet and Gateway use CIDR and IP notation, e.g. 172.28.0.0/16.
+	Subnet  string `json:"subnet"`
+	Gateway string `json:"gateway"`
+}
+
+type NetworkConnectRequest struct {
+	Container   string   `json:"container" binding:"required"`
+	Aliases     []string `json:"aliases"`
+	IPv4Address string   `json:"ipv4Address"`
+}
+
+type NetworkDisconnectRequest struct {
+	Container string `json:"container" binding:"required"`
+	Force     bool   `json:"force"`
+}
+
+func (myDocker DockerClient) CreateNetwork(request NetworkCreateRequest) (*types.NetworkCreateResponse, error) {
+
+	if request.Name == "" {
+		return nil, errdefs.InvalidParameter(errors.New("name is required"))
+	}
+
+	options := types.NetworkCreate{
+		Driver:     request.Driver,
+		Internal:   request.Internal,
+		Attachable: request.Attachable,
+		EnableIPv6: request.EnableIPv6,
+		Labels:     request.Labels,
+		Options:    request.Options,
+	}
+
+	if request.Subnet != "" || request.Gateway != "" {
+		options.IPAM = &network.IPAM{
+			Config: []network.IPAMConfig{{
+				Subnet:  request.Subnet,
+				Gateway: request.Gateway,
+			}},
+		}
+	}
+
+	created, err := myDocker.Client.NetworkCreate(context.Background(), request.Name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (myDocker DockerClient) ListNetworks(networkFilters filters.Args) ([]types.NetworkResource, error) {
+
+	return myDocker.Client.NetworkList(context.Background(), types.NetworkListOptions{Filters: networkFilters})
+}
+
+func (myDocker DockerClient) InspectNetwork(networkID string) (*types.NetworkResource, error) {
+
+	inspect, err := myDocker.Client.NetworkInspect(context.Background(), networkID, types.NetworkInspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &inspect, nil
+}
+
+func (myDocker DockerClient) RemoveNetwork(networkID string) error {
+
+	return myDocker.Client.NetworkRemove(context.Background(), networkID)
+}
+
+func (myDocker DockerClient) PruneNetworks(pruneFilters filters.Args) (types.NetworksPruneReport, error) {
+
+	return myDocker.Client.NetworksPrune(context.Background(), pruneFilters)
+}
+
+func (myDocker DockerClient) ConnectNetwork(networkID string, request NetworkConnectRequest) error {
+
+	if request.Container == "" {
+		return errdefs.InvalidParameter(errors.New("container is required"))
+	}
+
+	settings := &network.EndpointSettings{Aliases: request.Aliases}
+	if request.IPv4Address != "" {
+		settings.IPAMConfig = &network.EndpointIPAMConfig{IPv4Address: request.IPv4Address}
+	}
+
+	return myDocker.Client.NetworkConnect(context.Background(), networkID, request.Container, settings)
+}
+
+func (myDocker DockerClient) DisconnectNetwork(networkID string, request NetworkDisconnectRequest) error {
+
+	if request.Container == "" {
+		return errdefs.InvalidParameter(errors.New("container is required"))
+	}
+
+	return myDocker.Client.NetworkDisconnect(context.Background(), networkID, request.Container, request.Force)
+}
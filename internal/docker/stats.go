@@ -0,0 +1,185 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// StatsSummary is the part of Docker's stats most callers care about,
+// computed the same way as `docker stats` does.
+type StatsSummary struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Read          time.Time `json:"read"`
+	CPUPercent    float64   `json:"cpuPercent"`
+	MemoryUsage   uint64    `json:"memoryUsage"`
+	MemoryLimit   uint64    `json:"memoryLimit"`
+	MemoryPercent float64   `json:"memoryPercent"`
+	NetworkRx     uint64    `json:"networkRx"`
+	NetworkTx     uint64    `json:"networkTx"`
+	BlockRead     uint64    `json:"blockRead"`
+	BlockWrite    uint64    `json:"blockWrite"`
+	PIDs          uint64    `json:"pids"`
+}
+
+// StatsSample is what the API returns for one stats reading. Raw holds
+// Docker's own stats when the caller asks for them.
+type StatsSample struct {
+	Summary StatsSummary     `json:"summary"`
+	Raw     *types.StatsJSON `json:"raw,omitempty" swaggertype:"object"`
+}
+
+// StatsTotal adds up the summaries of several containers.
+type StatsTotal struct {
+	Containers  int     `json:"containers"`
+	CPUPercent  float64 `json:"cpuPercent"`
+	MemoryUsage uint64  `json:"memoryUsage"`
+	NetworkRx   uint64  `json:"networkRx"`
+	NetworkTx   uint64  `json:"networkTx"`
+	BlockRead   uint64  `json:"blockRead"`
+	BlockWrite  uint64  `json:"blockWrite"`
+	PIDs        uint64  `json:"pids"`
+}
+
+type AggregateStats struct {
+	Containers []StatsSummary `json:"containers"`
+	Total      StatsTotal     `json:"total"`
+}
+
+func Summarize(stats types.StatsJSON) StatsSummary {
+	summary := StatsSummary{
+		ID:          stats.ID,
+		Name:        strings.TrimPrefix(stats.Name, "/"),
+		Read:        stats.Read,
+		MemoryUsage: memoryUsage(stats.MemoryStats),
+		MemoryLimit: stats.MemoryStats.Limit,
+		PIDs:        stats.PidsStats.Current,
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	if cpuDelta > 0 && systemDelta > 0 {
+		summary.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	if summary.MemoryLimit > 0 {
+		summary.MemoryPercent = float64(summary.MemoryUsage) / float64(summary.MemoryLimit) * 100
+	}
+
+	for _, network := range stats.Networks {
+		summary.NetworkRx += network.RxBytes
+		summary.NetworkTx += network.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			summary.BlockRead += entry.Value
+		case "write":
+			summary.BlockWrite += entry.Value
+		}
+	}
+
+	return summary
+}
+
+// memoryUsage leaves the page cache out of the usage, like the Docker CLI.
+func memoryUsage(stats types.MemoryStats) uint64 {
+	for _, key := range []string{"total_inactive_file", "inactive_file"} {
+		if cache, ok := stats.Stats[key]; ok && cache < stats.Usage {
+			return stats.Usage - cache
+		}
+	}
+
+	return stats.Usage
+}
+
+// ContainerStats decodes the stats of the container and hands each sample
+// to each. Without stream a single sample is read.
+func (myDocker DockerClient) ContainerStats(ctx context.Context, containerID string, stream bool, each func(types.StatsJSON) error) error {
+
+	response, err := myDocker.Client.ContainerStats(ctx, containerID, stream)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var stats types.StatsJSON
+		err := decoder.Decode(&stats)
+		if err == io.EOF || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := each(stats); err != nil {
+			return err
+		}
+
+		if !stream {
+			return nil
+		}
+	}
+}
+
+// AllStats takes a sample of every running container in parallel.
+func (myDocker DockerClient) AllStats(ctx context.Context) (AggregateStats, error) {
+
+	containers, err := myDocker.Client.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return AggregateStats{}, err
+	}
+
+	summaries := make([]*StatsSummary, len(containers))
+
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		wg.Add(1)
+		go func(i int, containerID string) {
+			defer wg.Done()
+
+			myDocker.ContainerStats(ctx, containerID, false, func(stats types.StatsJSON) error {
+				summary := Summarize(stats)
+				summaries[i] = &summary
+				return nil
+			})
+		}(i, c.ID)
+	}
+	wg.Wait()
+
+	aggregate := AggregateStats{Containers: []StatsSummary{}}
+	for _, summary := range summaries {
+		// Containers that stopped while sampling have no stats.
+		if summary == nil {
+			continue
+		}
+
+		aggregate.Containers = append(aggregate.Containers, *summary)
+		aggregate.Total.Containers++
+		aggregate.Total.CPUPercent += summary.CPUPercent
+		aggregate.Total.MemoryUsage += summary.MemoryUsage
+		aggregate.Total.NetworkRx += summary.NetworkRx
+		aggregate.Total.NetworkTx += summary.NetworkTx
+		aggregate.Total.BlockRead += summary.BlockRead
+		aggregate.Total.BlockWrite += summary.BlockWrite
+		aggregate.Total.PIDs += summary.PIDs
+	}
+
+	return aggregate, nil
+}
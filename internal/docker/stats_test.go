@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+// sample returns stats whose CPU went from pre to total nanoseconds of
+// usage while the system went from preSystem to system.
+func sample(pre, total, preSystem, system uint64, onlineCPUs uint32, perCPU int) types.StatsJSON {
+
+	var stats types.StatsJSON
+	stats.PreCPUStats.CPUUsage.TotalUsage = pre
+	stats.PreCPUStats.SystemUsage = preSystem
+	stats.CPUStats.CPUUsage.TotalUsage = total
+	stats.CPUStats.SystemUsage = system
+	stats.CPUStats.OnlineCPUs = onlineCPUs
+	stats.CPUStats.CPUUsage.PercpuUsage = make([]uint64, perCPU)
+
+	return stats
+}
+
+func TestSummarizeCPU(t *testing.T) {
+
+	tests := map[string]struct {
+		stats types.StatsJSON
+		want  float64
+	}{
+		"online cpus":              {sample(100, 300, 1000, 2000, 4, 0), 80},
+		"per cpu usage fallback":   {sample(100, 300, 1000, 2000, 0, 2), 40},
+		"zero system delta":        {sample(100, 300, 2000, 2000, 4, 0), 0},
+		"first sample":             {sample(0, 300, 0, 0, 4, 0), 0},
+		"usage counter went back":  {sample(300, 100, 1000, 2000, 4, 0), 0},
+		"idle container":           {sample(300, 300, 1000, 2000, 4, 0), 0},
+		"system counter went back": {sample(100, 300, 2000, 1000, 4, 0), 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Summarize(test.stats).CPUPercent
+			if math.Abs(got-test.want) > 1e-9 {
+				t.Fatalf("got %v%%, want %v%%", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeMemory(t *testing.T) {
+
+	tests := map[string]struct {
+		memory  types.MemoryStats
+		usage   uint64
+		percent float64
+	}{
+		"cgroup v1": {
+			types.MemoryStats{Usage: 600, Limit: 1000, Stats: map[string]uint64{"total_inactive_file": 100, "inactive_file": 50}},
+			500, 50,
+		},
+		"cgroup v2": {
+			types.MemoryStats{Usage: 600, Limit: 1000, Stats: map[string]uint64{"inactive_file": 200}},
+			400, 40,
+		},
+		"no page cache": {
+			types.MemoryStats{Usage: 600, Limit: 1000},
+			600, 60,
+		},
+		"page cache larger than usage": {
+			types.MemoryStats{Usage: 600, Limit: 1000, Stats: map[string]uint64{"inactive_file": 700}},
+			600, 60,
+		},
+		"no limit": {
+			types.MemoryStats{Usage: 600},
+			600, 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			var stats types.StatsJSON
+			stats.MemoryStats = test.memory
+
+			summary := Summarize(stats)
+			if summary.MemoryUsage != test.usage || summary.MemoryLimit != test.memory.Limit {
+				t.Fatalf("got usage %d of %d, want %d of %d", summary.MemoryUsage, summary.MemoryLimit, test.usage, test.memory.Limit)
+			}
+			if math.Abs(summary.MemoryPercent-test.percent) > 1e-9 {
+				t.Fatalf("got %v%%, want %v%%", summary.MemoryPercent, test.percent)
+			}
+		})
+	}
+}
+
+func TestSummarizeIO(t *testing.T) {
+
+	stats := types.StatsJSON{
+		Name: "/web",
+		ID:   "0123456789ab",
+		Networks: map[string]types.NetworkStats{
+			"eth0": {RxBytes: 100, TxBytes: 10},
+			"eth1": {RxBytes: 200, TxBytes: 20},
+		},
+	}
+	stats.PidsStats.Current = 7
+	stats.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{
+		{Major: 8, Op: "Read", Value: 1000},
+		{Major: 8, Op: "Write", Value: 100},
+		{Major: 259, Op: "read", Value: 2000},
+		{Major: 259, Op: "write", Value: 200},
+		{Major: 8, Op: "Sync", Value: 50},
+		{Major: 8, Op: "Total", Value: 1100},
+	}
+
+	got := Summarize(stats)
+
+	want := StatsSummary{
+		ID:         "0123456789ab",
+		Name:       "web",
+		NetworkRx:  300,
+		NetworkTx:  30,
+		BlockRead:  3000,
+		BlockWrite: 300,
+		PIDs:       7,
+	}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
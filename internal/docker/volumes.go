@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+)
+
+type VolumeCreateRequest struct {
+	// Name is generated by the daemon when empty.
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	DriverOpts map[string]string `json:"driverOpts"`
+	Labels     map[string]string `json:"labels"`
+}
+
+func (myDocker DockerClient) CreateVolume(request VolumeCreateRequest) (*volume.Volume, error) {
+
+	created, err := myDocker.Client.VolumeCreate(context.Background(), volume.CreateOptions{
+		Name:       request.Name,
+		Driver:     request.Driver,
+		DriverOpts: request.DriverOpts,
+		Labels:     request.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (myDocker DockerClient) ListVolumes(volumeFilters filters.Args) (volume.ListResponse, error) {
+
+	return myDocker.Client.VolumeList(context.Background(), volume.ListOptions{Filters: volumeFilters})
+}
+
+func (myDocker DockerClient) InspectVolume(name string) (*volume.Volume, error) {
+
+	inspect, err := myDocker.Client.VolumeInspect(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inspect, nil
+}
+
+func (myDocker DockerClient) RemoveVolume(name string, force bool) error {
+
+	if name == "" {
+		return errdefs.InvalidParameter(errors.New("volume name is required"))
+	}
+
+	return myDocker.Client.VolumeRemove(context.Background(), name, force)
+}
+
+// PruneVolumes removes unused anonymous volumes, or named ones as well when
+// all is set.
+func (myDocker DockerClient) PruneVolumes(all bool, pruneFilters filters.Args) (types.VolumesPruneReport, error) {
+
+	if all {
+		pruneFilters.Add("all", "true")
+	}
+
+	return myDocker.Client.VolumesPrune(context.Background(), pruneFilters)
+}
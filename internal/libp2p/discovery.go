@@ -0,0 +1,80 @@
+package libp2p
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+)
+
+// DiscoveryService is the mDNS service every node advertises itself on.
+// Nodes in a cluster also advertise on a service named after it, so the
+// peers found there are known to share the cluster name. Service names are
+// at most 15 characters long (RFC 6335).
+const DiscoveryService = "_rcnode._udp"
+
+var clusterName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// PeerFound is called for every node found on the local network, with
+// inCluster set when it advertises the same cluster name as this one.
+type PeerFound func(info peer.AddrInfo, inCluster bool)
+
+type discoveryNotifee struct {
+	inCluster bool
+	found     PeerFound
+}
+
+func (n discoveryNotifee) HandlePeerFound(info peer.AddrInfo) {
+	n.found(info, n.inCluster)
+}
+
+// ValidateCluster checks the cluster name given to a node.
+func ValidateCluster(cluster string) error {
+	if cluster != "" && !clusterName.MatchString(cluster) {
+		return fmt.Errorf("invalid cluster name %q, expected up to 32 lowercase letters, digits and dashes", cluster)
+	}
+	return nil
+}
+
+// ClusterService is the mDNS service of the nodes in cluster. The name is
+// hashed to keep the service name within 15 characters.
+func ClusterService(cluster string) string {
+	sum := sha256.Sum256([]byte(cluster))
+	return "_rc-" + hex.EncodeToString(sum[:4]) + "._udp"
+}
+
+// StartDiscovery advertises the node on the local network with mDNS and
+// reports the other nodes it finds. It returns a function that stops it.
+func StartDiscovery(h host.Host, cluster string, found PeerFound) (func(), error) {
+
+	if err := ValidateCluster(cluster); err != nil {
+		return nil, err
+	}
+
+	services := []mdns.Service{
+		mdns.NewMdnsService(h, DiscoveryService, discoveryNotifee{found: found}),
+	}
+
+	if cluster != "" {
+		services = append(services, mdns.NewMdnsService(h, ClusterService(cluster), discoveryNotifee{inCluster: true, found: found}))
+	}
+
+	stop := func() {
+		for _, service := range services {
+			service.Close()
+		}
+	}
+
+	for _, service := range services {
+		if err := service.Start(); err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to start mDNS discovery: %w", err)
+		}
+	}
+
+	return stop, nil
+}
@@ -0,0 +1,28 @@
+package libp2p
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServiceNamesLength(t *testing.T) {
+
+	names := []string{
+		DiscoveryService,
+		ClusterService("a"),
+		ClusterService(strings.Repeat("a", 32)),
+	}
+
+	for _, name := range names {
+		// The length limit of RFC 6335 applies to the name without the
+		// leading underscore and the protocol.
+		service := strings.TrimSuffix(strings.TrimPrefix(name, "_"), "._udp")
+		if len(service) > 15 {
+			t.Errorf("service name %q is longer than 15 characters", service)
+		}
+	}
+
+	if ClusterService("lab") == ClusterService("lab2") {
+		t.Fatal("clusters share a service name")
+	}
+}
@@ -0,0 +1,114 @@
+package libp2p
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+)
+
+// DefaultIdentityPath is where the node keeps its private key when no other
+// file is given: remote-containers/identity.key in the user config dir.
+func DefaultIdentityPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "remote-containers", "identity.key"), nil
+}
+
+// LoadIdentity reads the private key the node is identified by from path,
+// so its peer ID survives restarts. A new Ed25519 key is created and saved
+// there on first run.
+func LoadIdentity(path string) (crypto.PrivKey, error) {
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return createIdentity(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity %s: %w", path, err)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+		logger.Warnf("Identity %s is readable by other users, consider chmod 600", path)
+	}
+
+	key, err := crypto.UnmarshalPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity %s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+func createIdentity(path string) (crypto.PrivKey, error) {
+
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity dir: %w", err)
+	}
+
+	// Written to a temporary file first so an interrupted run never leaves
+	// a truncated key behind.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".identity-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to save identity: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save identity: %w", err)
+	}
+
+	logger.Infof("Created a new identity in %s", path)
+
+	return key, nil
+}
+
+// LocalIPs returns the IPv4 addresses of the network interfaces that are
+// up, loopback first, which are the addresses the node can be reached at.
+func LocalIPs() []net.IP {
+	ips := []net.IP{net.IPv4(127, 0, 0, 1)}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ips
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		ips = append(ips, ipNet.IP)
+	}
+
+	return ips
+}
@@ -0,0 +1,74 @@
+package libp2p
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestLoadIdentity(t *testing.T) {
+
+	// The directory of the key is created too.
+	path := filepath.Join(t.TempDir(), "remote-containers", "identity.key")
+
+	created, err := LoadIdentity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("the key was not saved: %s", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("got mode %o, want 600", info.Mode().Perm())
+	}
+
+	loaded, err := LoadIdentity(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createdID, err := peer.IDFromPrivateKey(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedID, err := peer.IDFromPrivateKey(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if createdID != loadedID {
+		t.Fatalf("got peer ID %s after a restart, want %s", loadedID, createdID)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d files next to the key, want only the key", len(entries))
+	}
+}
+
+func TestLoadIdentityRejectsCorruptKey(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "identity.key")
+	if err := os.WriteFile(path, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadIdentity(path); err == nil {
+		t.Fatal("a corrupt key was loaded")
+	}
+
+	// The corrupt key is kept rather than replaced with a new identity.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "not a key" {
+		t.Fatalf("the corrupt key was overwritten with %d bytes", len(data))
+	}
+}
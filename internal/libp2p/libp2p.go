@@ -3,63 +3,99 @@ package libp2p
 import (
 	"bufio"
 	"context"
-	"crypto/rand"
 	"fmt"
-	"io"
-	"log"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 
+	logging "github.com/ipfs/go-log/v2"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/core/pnet"
 	"github.com/multiformats/go-multiaddr"
 )
 
-func NewHost(ctx context.Context) (host.Host, error) {
+var logger = logging.Logger("remote-containers/p2p")
 
-	port, err := GetFreePort()
+// NewHost starts a libp2p host identified by the given private key and
+// listening on listen, a host:port pair where port 0 picks a free port. It
+// fails when the address can't be bound. With a pre-shared key the host
+// only talks to the nodes of the private network sharing it. Extra libp2p
+// options, such as a connection gater, are passed on to libp2p.New.
+func NewHost(ctx context.Context, identity crypto.PrivKey, listen string, psk pnet.PSK, options ...libp2p.Option) (host.Host, error) {
 
+	address, portValue, err := net.SplitHostPort(listen)
 	if err != nil {
-		log.Fatalf("Error to check open ports: %s \n", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("invalid listen address %q: %w", listen, err)
 	}
 
-	r := rand.Reader
+	port, err := strconv.Atoi(portValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listen port %q", portValue)
+	}
+
+	h, err := makeHost(address, port, identity, psk, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the libp2p node on %s: %w", listen, err)
+	}
+
+	for _, addr := range h.Network().ListenAddresses() {
+		logger.Infof("libp2p node listening on %s", addr)
+	}
 
-	h, err := makeHost(port, r)
+	if psk != nil {
+		logger.Infof("Private network with swarm key %s", Fingerprint(psk))
+	}
 
-	return h, err
+	return h, nil
 }
 
-func makeHost(port int, randomness io.Reader) (host.Host, error) {
-	// Creates a new RSA key pair for this host.
-	prvKey, _, err := crypto.GenerateKeyPairWithReader(crypto.RSA, 2048, randomness)
+func makeHost(address string, port int, prvKey crypto.PrivKey, psk pnet.PSK, options ...libp2p.Option) (host.Host, error) {
+
+	// 0.0.0.0 will listen on any interface device.
+	if address == "" {
+		address = "0.0.0.0"
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid listen address %q, expected an IP", address)
+	}
+
+	protocol := "ip4"
+	if ip.To4() == nil {
+		protocol = "ip6"
+	}
+
+	sourceMultiAddr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/%s/%s/tcp/%d", protocol, address, port))
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	// 0.0.0.0 will listen on any interface device.
-	sourceMultiAddr, _ := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/0.0.0.0/tcp/%d", port))
+	// A nil key leaves the node on the public network.
+	if psk != nil {
+		options = append(options, libp2p.PrivateNetwork(psk))
+	}
 
 	// libp2p.New constructs a new libp2p Host.
 	// Other options can be added here.
-	return libp2p.New(
+	return libp2p.New(append([]libp2p.Option{
 		libp2p.ListenAddrs(sourceMultiAddr),
 		libp2p.Identity(prvKey),
-	)
+	}, options...)...)
 }
 
 func StartPeer(ctx context.Context, h host.Host, streamHandler network.StreamHandler) {
 	// Set a function as stream handler.
 	// This function is called when a peer connects, and starts a stream with this protocol.
 	// Only applies on the receiving side.
-	h.SetStreamHandler("/stream/protocol", streamHandler)
+	h.SetStreamHandler(communication.MessageProtocol, streamHandler)
 
 	// Let's get the actual TCP port from our listen multiaddr, in case we're using 0 (default; random available port).
 	var port string
@@ -71,64 +107,39 @@ func StartPeer(ctx context.Context, h host.Host, streamHandler network.StreamHan
 	}
 
 	if port == "" {
-		log.Println("was not able to find actual local port")
+		logger.Warn("Was not able to find actual local port")
 		return
 	}
 
-	log.Printf("To connect to this machine use: \n")
-	log.Printf("Local: /ip4/127.0.0.1/tcp/%v/p2p/%s\n", port, h.ID())
-	log.Printf("Same network: /ip4/%v/tcp/%v/p2p/%s\n", GetLocalIP().String(), port, h.ID())
-	log.Println("Or replace 127.0.0.1 to your public ip.")
-	log.Println("Waiting for incoming connection")
+	logger.Info("To connect to this machine use:")
+	logger.Infof("Local: /ip4/127.0.0.1/tcp/%v/p2p/%s", port, h.ID())
+	logger.Infof("Same network: /ip4/%v/tcp/%v/p2p/%s", GetLocalIP().String(), port, h.ID())
+	logger.Info("Or replace 127.0.0.1 to your public ip.")
+	logger.Info("Waiting for incoming connection")
 }
 
-func StartPeerAndConnect(ctx context.Context, h host.Host, destination string) (*network.Stream, error) {
-	log.Println("This node's multiaddresses:")
-	for _, la := range h.Addrs() {
-		log.Printf(" - %v\n", la)
-	}
-	log.Println()
+// AddPeerAddress stores the address of the destination, a multiaddr ending
+// in /p2p/<peer id>, in the peerstore and returns the ID of the peer, so it
+// can be dialed and redialed by ID.
+func AddPeerAddress(h host.Host, destination string) (peer.ID, error) {
 
 	// Turn the destination into a multiaddr.
 	maddr, err := multiaddr.NewMultiaddr(destination)
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		return "", fmt.Errorf("invalid peer address %q: %w", destination, err)
 	}
 
 	// Extract the peer ID from the multiaddr.
 	info, err := peer.AddrInfoFromP2pAddr(maddr)
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		return "", fmt.Errorf("invalid peer address %q: %w", destination, err)
 	}
 
 	// Add the destination's peer multiaddress in the peerstore.
 	// This will be used during connection and stream creation by libp2p.
 	h.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.PermanentAddrTTL)
 
-	// Start a stream with the destination.
-	// Multiaddress of the destination peer is fetched from the peerstore using 'peerId'.
-	s, err := h.NewStream(context.Background(), info.ID, "/stream/protocol")
-	if err != nil {
-		log.Println(err)
-		return nil, err
-	}
-	log.Println("Established connection to destination")
-
-	return &s, nil
-}
-
-func GetFreePort() (port int, err error) {
-	var a *net.TCPAddr
-	if a, err = net.ResolveTCPAddr("tcp", "localhost:0"); err == nil {
-		var l *net.TCPListener
-		if l, err = net.ListenTCP("tcp", a); err == nil {
-			defer l.Close()
-			return l.Addr().(*net.TCPAddr).Port, nil
-		}
-	}
-	return
+	return info.ID, nil
 }
 
 func Input(label string) string {
@@ -147,7 +158,7 @@ func Input(label string) string {
 func GetLocalIP() net.IP {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 	defer conn.Close()
 
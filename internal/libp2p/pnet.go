@@ -0,0 +1,69 @@
+package libp2p
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/pnet"
+)
+
+// ErrHandshakeFailed is what a dial fails with when the peer drops the
+// connection before it is secured, as nodes of different private networks
+// do.
+var ErrHandshakeFailed = errors.New("secure handshake failed, the peer may use a different swarm key or none")
+
+// SwarmKey encodes a new random pre-shared key in the format of the swarm
+// key files of libp2p:
+//
+//	/key/swarm/psk/1.0.0/
+//	/base16/
+//	<64 hex digits>
+func SwarmKey() ([]byte, error) {
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte("/key/swarm/psk/1.0.0/\n/base16/\n" + hex.EncodeToString(key) + "\n"), nil
+}
+
+// LoadSwarmKey reads the pre-shared key of a private network from path.
+func LoadSwarmKey(path string) (pnet.PSK, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swarm key: %w", err)
+	}
+
+	psk, err := pnet.DecodeV1PSK(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid swarm key %s: %w", path, err)
+	}
+
+	return psk, nil
+}
+
+// Fingerprint identifies a pre-shared key without revealing it, so two
+// nodes can tell whether they were given the same one.
+func Fingerprint(psk pnet.PSK) string {
+	sum := sha256.Sum256(psk)
+	return hex.EncodeToString(sum[:8])
+}
+
+// DialError wraps the error of a dial with ErrHandshakeFailed when it may
+// come from a swarm key mismatch. libp2p can't tell a wrong pre-shared key
+// apart from any other garbled handshake.
+func DialError(err error) error {
+	if err != nil && strings.Contains(err.Error(), "failed to negotiate security protocol") {
+		return fmt.Errorf("%w: %v", ErrHandshakeFailed, err)
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+package libp2p
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDialError(t *testing.T) {
+
+	mismatch := errors.New("failed to dial: failed to negotiate security protocol: read: connection reset by peer")
+	if err := DialError(mismatch); !errors.Is(err, ErrHandshakeFailed) {
+		t.Fatalf("got %v, want %v", err, ErrHandshakeFailed)
+	}
+
+	refused := errors.New("failed to dial: connection refused")
+	if err := DialError(refused); err != refused {
+		t.Fatalf("got %v, want the error unchanged", err)
+	}
+
+	if DialError(nil) != nil {
+		t.Fatal("nil error wrapped")
+	}
+}
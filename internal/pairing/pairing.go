@@ -0,0 +1,359 @@
+package pairing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	p2p "github.com/jhonjoao/remote-containers/internal/libp2p"
+	"github.com/jhonjoao/remote-containers/internal/trust"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+var logger = logging.Logger("remote-containers/pairing")
+
+const (
+	DefaultTTL = 10 * time.Minute
+	MaxTTL     = 24 * time.Hour
+	// Timeout bounds the whole exchange on a pairing stream, dial
+	// included.
+	Timeout = 30 * time.Second
+)
+
+var (
+	ErrTokenNotFound = errors.New("pairing token not found")
+	ErrTokenRejected = errors.New("pairing token is unknown, expired or already used")
+	ErrNoAddress     = errors.New("the node has no address to put in a pairing token")
+	ErrRefused       = errors.New("pairing refused")
+)
+
+// Token is a pairing token as listed by the API. Name and Role are those
+// the joining node is authorized with. Token, the string to hand to the
+// joining node, is only known when the token is issued.
+type Token struct {
+	Id        string    `json:"id"`
+	Token     string    `json:"token,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type issued struct {
+	Token
+	secret []byte
+}
+
+// Pairing issues the one-time tokens other nodes join this one with, and
+// joins other nodes with theirs. Both nodes of a pairing add each other to
+// their trust stores for good.
+type Pairing struct {
+	store *trust.Store
+	host  host.Host
+	// Paired is called once a pairing added a peer to the store. addrs are
+	// those the peer was reached at, none when it joined this node.
+	Paired func(entry trust.Entry, addrs []multiaddr.Multiaddr)
+
+	mu      sync.Mutex
+	tokens  map[string]issued
+	joining map[peer.ID]bool
+}
+
+func New(store *trust.Store) *Pairing {
+	return &Pairing{
+		store:   store,
+		Paired:  func(trust.Entry, []multiaddr.Multiaddr) {},
+		tokens:  map[string]issued{},
+		joining: map[peer.ID]bool{},
+	}
+}
+
+// Start serves the pairing requests of other nodes on h.
+func (p *Pairing) Start(h host.Host) {
+	p.mu.Lock()
+	p.host = h
+	p.mu.Unlock()
+
+	h.SetStreamHandler(communication.PairingProtocol, p.handleStream)
+}
+
+// Issue creates a token for a single node to join this one within ttl,
+// which is then authorized with the given name and role.
+func (p *Pairing) Issue(name, role string, ttl time.Duration) (Token, error) {
+
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		return Token{}, fmt.Errorf("invalid token lifetime %s, expected at most %s", ttl, MaxTTL)
+	}
+
+	if err := trust.ValidateRole(role); err != nil {
+		return Token{}, err
+	}
+	if role == "" {
+		role = trust.RoleAdmin
+	}
+
+	addr, err := p.address()
+	if err != nil {
+		return Token{}, err
+	}
+
+	secret := make([]byte, SecretSize)
+	_, err = rand.Read(secret)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token := issued{
+		Token: Token{
+			Id:        tokenID(secret),
+			Token:     encodeToken(secret, addr),
+			Name:      name,
+			Role:      role,
+			ExpiresAt: time.Now().Add(ttl),
+		},
+		secret: secret,
+	}
+
+	p.mu.Lock()
+	p.tokens[token.Id] = token
+	p.mu.Unlock()
+
+	logger.Infof("Issued pairing token %s, valid until %s", token.Id, token.ExpiresAt.Format(time.RFC3339))
+
+	return token.Token, nil
+}
+
+// address is the multiaddr put in the tokens, preferring one reachable
+// from other machines.
+func (p *Pairing) address() (multiaddr.Multiaddr, error) {
+
+	p.mu.Lock()
+	h := p.host
+	p.mu.Unlock()
+
+	if h == nil || len(h.Addrs()) == 0 {
+		return nil, ErrNoAddress
+	}
+
+	addrs := h.Addrs()
+	addr := addrs[0]
+	for _, candidate := range addrs {
+		if !manet.IsIPLoopback(candidate) {
+			addr = candidate
+			break
+		}
+	}
+
+	id, err := multiaddr.NewComponent("p2p", h.ID().String())
+	if err != nil {
+		return nil, err
+	}
+
+	return addr.Encapsulate(id), nil
+}
+
+// List returns the tokens that can still be used, without their secret.
+func (p *Pairing) List() []Token {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.prune()
+
+	list := make([]Token, 0, len(p.tokens))
+	for _, token := range p.tokens {
+		listed := token.Token
+		listed.Token = ""
+		list = append(list, listed)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ExpiresAt.Before(list[j].ExpiresAt)
+	})
+
+	return list
+}
+
+// Revoke deletes a token before it expires.
+func (p *Pairing) Revoke(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.prune()
+
+	if _, ok := p.tokens[id]; !ok {
+		return ErrTokenNotFound
+	}
+	delete(p.tokens, id)
+
+	logger.Infof("Revoked pairing token %s", id)
+	return nil
+}
+
+// Allow reports whether a peer missing from the store may connect to pair:
+// any peer while a token of this node can be used, or the node this one is
+// joining.
+func (p *Pairing) Allow(id peer.ID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.prune()
+
+	return p.joining[id] || len(p.tokens) > 0
+}
+
+func (p *Pairing) prune() {
+	now := time.Now()
+	for id, token := range p.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(p.tokens, id)
+		}
+	}
+}
+
+// redeem consumes the token with the given secret.
+func (p *Pairing) redeem(secret []byte) (Token, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.prune()
+
+	token, ok := p.tokens[tokenID(secret)]
+	if !ok || subtle.ConstantTimeCompare(token.secret, secret) != 1 {
+		return Token{}, false
+	}
+	delete(p.tokens, token.Id)
+
+	return token.Token, true
+}
+
+func (p *Pairing) handleStream(s network.Stream) {
+	defer s.Close()
+
+	s.SetDeadline(time.Now().Add(Timeout))
+	remote := s.Conn().RemotePeer()
+
+	respond := func(err error) {
+		var response communication.PairingResponse
+		if err != nil {
+			logger.Warnf("Rejected pairing of peer %s: %s", remote, err)
+			response.Error = err.Error()
+		}
+		json.NewEncoder(s).Encode(response)
+	}
+
+	var request communication.PairingRequest
+	err := json.NewDecoder(s).Decode(&request)
+	if err != nil {
+		respond(fmt.Errorf("invalid pairing request: %w", err))
+		return
+	}
+
+	token, ok := p.redeem(request.Secret)
+	if !ok {
+		respond(ErrTokenRejected)
+		return
+	}
+
+	entry := trust.Entry{ID: remote.String(), Name: token.Name, Role: token.Role}
+	err = p.store.Add(entry)
+	if err != nil {
+		respond(err)
+		return
+	}
+
+	respond(nil)
+	logger.Infof("Peer %s joined with pairing token %s", remote, token.Id)
+
+	p.Paired(entry, nil)
+}
+
+// Join pairs this node with the one that issued token, which is then
+// authorized here with the given name and role, viewer by default.
+func (p *Pairing) Join(ctx context.Context, token, name, role string) (trust.Entry, error) {
+
+	if err := trust.ValidateRole(role); err != nil {
+		return trust.Entry{}, err
+	}
+	if role == "" {
+		role = trust.RoleViewer
+	}
+
+	secret, info, err := decodeToken(token)
+	if err != nil {
+		return trust.Entry{}, err
+	}
+
+	p.mu.Lock()
+	h := p.host
+	if h == nil {
+		p.mu.Unlock()
+		return trust.Entry{}, errors.New("pairing is not started")
+	}
+	if h.ID() == info.ID {
+		p.mu.Unlock()
+		return trust.Entry{}, fmt.Errorf("%w: it was issued by this node", ErrInvalidToken)
+	}
+	p.joining[info.ID] = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.joining, info.ID)
+		p.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	h.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.TempAddrTTL)
+
+	s, err := h.NewStream(ctx, info.ID, communication.PairingProtocol)
+	if err != nil {
+		return trust.Entry{}, fmt.Errorf("failed to reach peer %s: %w", info.ID, p2p.DialError(err))
+	}
+	defer s.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		s.SetDeadline(deadline)
+	}
+
+	err = json.NewEncoder(s).Encode(communication.PairingRequest{Secret: secret})
+	if err != nil {
+		return trust.Entry{}, fmt.Errorf("failed to send pairing request: %w", err)
+	}
+
+	var response communication.PairingResponse
+	err = json.NewDecoder(s).Decode(&response)
+	if err != nil {
+		return trust.Entry{}, fmt.Errorf("failed to read pairing response: %w", err)
+	}
+	if response.Error != "" {
+		return trust.Entry{}, fmt.Errorf("%w by peer %s: %s", ErrRefused, info.ID, response.Error)
+	}
+
+	entry := trust.Entry{ID: info.ID.String(), Name: name, Role: role}
+	err = p.store.Add(entry)
+	if err != nil {
+		return trust.Entry{}, err
+	}
+
+	logger.Infof("Joined peer %s with a pairing token", info.ID)
+
+	p.Paired(entry, info.Addrs)
+	return entry, nil
+}
@@ -0,0 +1,238 @@
+package pairing
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jhonjoao/remote-containers/internal/trust"
+	"github.com/libp2p/go-libp2p/core/host"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+type node struct {
+	host    host.Host
+	store   *trust.Store
+	pairing *Pairing
+}
+
+// newNodes starts count linked nodes in memory, each with an empty trust
+// store and pairing started.
+func newNodes(t *testing.T, count int) []node {
+	t.Helper()
+
+	network := mocknet.New()
+	t.Cleanup(func() { network.Close() })
+
+	nodes := make([]node, count)
+	for i := range nodes {
+		h, err := network.GenPeer()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		store, err := trust.Load(filepath.Join(t.TempDir(), "authorized_peers.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nodes[i] = node{host: h, store: store, pairing: New(store)}
+		nodes[i].pairing.Start(h)
+	}
+
+	if err := network.LinkAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	return nodes
+}
+
+func TestJoin(t *testing.T) {
+
+	nodes := newNodes(t, 2)
+	issuer, joiner := nodes[0], nodes[1]
+
+	token, err := issuer.pairing.Issue("laptop", trust.RoleViewer, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !issuer.pairing.Allow(joiner.host.ID()) {
+		t.Fatal("unknown peer not allowed to pair while a token is open")
+	}
+
+	entry, err := joiner.pairing.Join(context.Background(), token.Token, "server", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := trust.Entry{ID: issuer.host.ID().String(), Name: "server", Role: trust.RoleViewer}
+	if entry != want {
+		t.Fatalf("joined %+v, want %+v", entry, want)
+	}
+	if got, _ := joiner.store.Lookup(issuer.host.ID()); got != want {
+		t.Fatalf("joining node stored %+v, want %+v", got, want)
+	}
+
+	want = trust.Entry{ID: joiner.host.ID().String(), Name: "laptop", Role: trust.RoleViewer}
+	if got, _ := issuer.store.Lookup(joiner.host.ID()); got != want {
+		t.Fatalf("issuing node stored %+v, want %+v", got, want)
+	}
+
+	if len(issuer.pairing.List()) != 0 || issuer.pairing.Allow(joiner.host.ID()) {
+		t.Fatal("token still open after it was used")
+	}
+}
+
+func TestJoinRole(t *testing.T) {
+
+	nodes := newNodes(t, 2)
+	issuer, joiner := nodes[0], nodes[1]
+
+	token, err := issuer.pairing.Issue("", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := joiner.pairing.Join(context.Background(), token.Token, "", "owner"); err == nil {
+		t.Fatal("joined with an invalid role")
+	}
+
+	entry, err := joiner.pairing.Join(context.Background(), token.Token, "", trust.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Role != trust.RoleAdmin {
+		t.Fatalf("got role %q, want %q", entry.Role, trust.RoleAdmin)
+	}
+
+	// Tokens issued without a role make the joining node an admin.
+	if got, _ := issuer.store.Lookup(joiner.host.ID()); got.Role != trust.RoleAdmin {
+		t.Fatalf("got role %q, want %q", got.Role, trust.RoleAdmin)
+	}
+}
+
+func TestTokenSingleUse(t *testing.T) {
+
+	nodes := newNodes(t, 3)
+	issuer, first, second := nodes[0], nodes[1], nodes[2]
+
+	token, err := issuer.pairing.Issue("", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := first.pairing.Join(context.Background(), token.Token, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = second.pairing.Join(context.Background(), token.Token, "", "")
+	if !errors.Is(err, ErrRefused) {
+		t.Fatalf("got %v, want %v", err, ErrRefused)
+	}
+	if _, ok := issuer.store.Lookup(second.host.ID()); ok {
+		t.Fatal("second node authorized with a used token")
+	}
+	if _, ok := second.store.Lookup(issuer.host.ID()); ok {
+		t.Fatal("refused pairing added the issuing node")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+
+	nodes := newNodes(t, 2)
+	issuer, joiner := nodes[0], nodes[1]
+
+	token, err := issuer.pairing.Issue("", "", 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if len(issuer.pairing.List()) != 0 {
+		t.Fatal("expired token still listed")
+	}
+	if issuer.pairing.Allow(joiner.host.ID()) {
+		t.Fatal("unknown peer allowed after the token expired")
+	}
+
+	_, err = joiner.pairing.Join(context.Background(), token.Token, "", "")
+	if !errors.Is(err, ErrRefused) {
+		t.Fatalf("got %v, want %v", err, ErrRefused)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+
+	nodes := newNodes(t, 2)
+	issuer, joiner := nodes[0], nodes[1]
+
+	token, err := issuer.pairing.Issue("", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := issuer.pairing.Revoke(token.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := issuer.pairing.Revoke(token.Id); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("got %v, want %v", err, ErrTokenNotFound)
+	}
+
+	_, err = joiner.pairing.Join(context.Background(), token.Token, "", "")
+	if !errors.Is(err, ErrRefused) {
+		t.Fatalf("got %v, want %v", err, ErrRefused)
+	}
+}
+
+func TestIssueRejects(t *testing.T) {
+
+	issuer := newNodes(t, 1)[0]
+
+	tests := map[string]struct {
+		role string
+		ttl  time.Duration
+	}{
+		"negative ttl": {"", -time.Minute},
+		"ttl too long": {"", MaxTTL + time.Minute},
+		"invalid role": {"owner", time.Minute},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := issuer.pairing.Issue("", test.role, test.ttl); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestJoinInvalidToken(t *testing.T) {
+
+	nodes := newNodes(t, 1)
+
+	token, err := nodes[0].pairing.Issue("", "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := map[string]string{
+		"not base64":   "not a token!",
+		"secret only":  base64.RawURLEncoding.EncodeToString(make([]byte, SecretSize)),
+		"own token":    token.Token,
+		"garbled addr": token.Token[:30],
+	}
+
+	for name, value := range tokens {
+		t.Run(name, func(t *testing.T) {
+			_, err := nodes[0].pairing.Join(context.Background(), value, "", "")
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("got %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
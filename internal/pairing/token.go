@@ -0,0 +1,50 @@
+package pairing
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// SecretSize is the number of random bytes of the secret of a token.
+const SecretSize = 16
+
+var ErrInvalidToken = errors.New("invalid pairing token")
+
+// encodeToken packs the secret and the multiaddr of the node issuing the
+// token, with its peer ID, into a string that can be pasted in a terminal.
+func encodeToken(secret []byte, addr multiaddr.Multiaddr) string {
+	return base64.RawURLEncoding.EncodeToString(append(append([]byte{}, secret...), addr.Bytes()...))
+}
+
+// decodeToken returns the secret of the token and the node that issued it.
+func decodeToken(token string) ([]byte, *peer.AddrInfo, error) {
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) <= SecretSize {
+		return nil, nil, ErrInvalidToken
+	}
+
+	addr, err := multiaddr.NewMultiaddrBytes(data[SecretSize:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	info, err := peer.AddrInfoFromP2pAddr(addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	return data[:SecretSize], info, nil
+}
+
+// tokenID names a token in the API without revealing its secret.
+func tokenID(secret []byte) string {
+	sum := sha256.Sum256(secret)
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,68 @@
+package peers
+
+import (
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Discovered is a node found on the local network.
+type Discovered struct {
+	Peer  string   `json:"peer"`
+	Addrs []string `json:"addrs"`
+	// InCluster is set when the node advertises the same cluster name as
+	// this one.
+	InCluster bool      `json:"inCluster"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// Discover records a node found on the local network. It returns true the
+// first time the node is found, or found in the cluster, unless this node
+// already keeps a connection to it.
+func (m *Manager) Discover(info peer.AddrInfo, inCluster bool) bool {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found := Discovered{
+		Peer:      info.ID.String(),
+		Addrs:     []string{},
+		InCluster: inCluster,
+		LastSeen:  time.Now(),
+	}
+	for _, addr := range info.Addrs {
+		found.Addrs = append(found.Addrs, addr.String())
+	}
+
+	previous, seen := m.discovered[info.ID]
+	if seen && previous.InCluster {
+		found.InCluster = true
+	}
+	m.discovered[info.ID] = found
+
+	_, supervised := m.supervised[info.ID]
+
+	return (!seen || found.InCluster != previous.InCluster) && !supervised
+}
+
+// Discovered returns the nodes found on the local network that are not
+// connected, sorted by ID.
+func (m *Manager) Discovered() []Discovered {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := []Discovered{}
+	for id, found := range m.discovered {
+		if _, connected := m.peers[id]; !connected {
+			list = append(list, found)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Peer < list[j].Peer
+	})
+
+	return list
+}
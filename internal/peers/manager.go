@@ -0,0 +1,322 @@
+package peers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+)
+
+var logger = logging.Logger("remote-containers/peers")
+
+const (
+	PingInterval = 15 * time.Second
+	PingTimeout  = 5 * time.Second
+	// MaxPingFailures is how many pings in a row may fail before the peer
+	// is taken as disconnected.
+	MaxPingFailures = 3
+)
+
+var (
+	ErrNoPeers       = errors.New("no peer is connected")
+	ErrPeerNotFound  = errors.New("peer is not connected")
+	ErrPeerAmbiguous = errors.New("several peers are connected, select one")
+)
+
+// Peer is a connected node: the stream messages are exchanged on and the
+// requests sent to it that still wait for an answer.
+type Peer struct {
+	ID      peer.ID
+	Pending *communication.PendingRequests
+	// Role is the one the peer was authorized with.
+	Role string
+	// ConnectedAt is when the current stream to the peer was opened.
+	ConnectedAt time.Time
+
+	stream network.Stream
+	done   chan struct{}
+	// writeMu keeps envelopes written from concurrent handlers from
+	// interleaving on the stream.
+	writeMu sync.Mutex
+}
+
+// RemoteAddr is the address the stream to the peer is connected to.
+func (p *Peer) RemoteAddr() string {
+	return p.stream.Conn().RemoteMultiaddr().String()
+}
+
+// Send writes the envelope to the peer. It is safe for concurrent use.
+func (p *Peer) Send(envelope communication.Envelope) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	return communication.WriteEnvelope(p.stream, envelope)
+}
+
+// Authorizer returns the role of the peer, or an error when it may not
+// talk to this node.
+type Authorizer func(id peer.ID) (role string, err error)
+
+// Handler processes the messages a peer sends until the channel is closed,
+// which happens when its stream ends.
+type Handler func(p *Peer, messages <-chan communication.Envelope)
+
+// Manager keeps the streams of every connected peer, so the node can talk
+// to many of them at once.
+type Manager struct {
+	host      host.Host
+	handle    Handler
+	authorize Authorizer
+
+	mu         sync.RWMutex
+	peers      map[peer.ID]*Peer
+	aliases    map[string]peer.ID
+	supervised map[peer.ID]*Supervision
+	discovered map[peer.ID]Discovered
+	events     []Event
+}
+
+func NewManager(h host.Host, handle Handler, authorize Authorizer) *Manager {
+	return &Manager{
+		host:       h,
+		handle:     handle,
+		authorize:  authorize,
+		peers:      map[peer.ID]*Peer{},
+		aliases:    map[string]peer.ID{},
+		supervised: map[peer.ID]*Supervision{},
+		discovered: map[peer.ID]Discovered{},
+	}
+}
+
+// SetAlias lets the peer be selected by name as well as by its ID.
+func (m *Manager) SetAlias(name string, id peer.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.aliases[name] = id
+}
+
+// Alias returns the name the peer was given, if any.
+func (m *Manager) Alias(id peer.ID) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for name, aliased := range m.aliases {
+		if aliased == id {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func (m *Manager) Host() host.Host {
+	return m.host
+}
+
+// HandleStream is the network.StreamHandler for streams opened by other
+// nodes.
+func (m *Manager) HandleStream(s network.Stream) {
+	m.Add(s)
+}
+
+// Authorized wraps the handler of another protocol so it only serves the
+// peers allowed to talk to this node, with their role. The peer has to be
+// connected already, so peers trusted on first use are only pinned by the
+// handshake of the message stream.
+func (m *Manager) Authorized(handler func(s network.Stream, role string)) network.StreamHandler {
+	return func(s network.Stream) {
+		role, err := "", ErrPeerNotFound
+		if _, connected := m.get(s.Conn().RemotePeer()); connected {
+			role, err = m.authorize(s.Conn().RemotePeer())
+		}
+		if err != nil {
+			logger.Warnf("Rejected %s stream of peer %s: %s", s.Protocol(), s.Conn().RemotePeer(), err)
+			s.Reset()
+			return
+		}
+
+		handler(s, role)
+	}
+}
+
+// Add starts exchanging messages with the peer at the other end of s. A
+// previous stream to the same peer is closed and replaced. A peer that is
+// not authorized has its stream reset instead.
+func (m *Manager) Add(s network.Stream) (*Peer, error) {
+
+	id := s.Conn().RemotePeer()
+
+	role, err := m.authorize(id)
+	if err != nil {
+		logger.Warnf("Rejected stream of peer %s: %s", id, err)
+		s.Reset()
+		return nil, err
+	}
+
+	p := &Peer{
+		ID:          id,
+		Role:        role,
+		Pending:     communication.NewPendingRequests(),
+		ConnectedAt: time.Now(),
+		stream:      s,
+		done:        make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	previous := m.peers[p.ID]
+	m.peers[p.ID] = p
+	m.mu.Unlock()
+
+	if previous != nil {
+		previous.stream.Reset()
+	}
+
+	m.record(p.ID, EventConnected, nil)
+	logger.Infof("Peer %s connected from %s", p.ID, s.Conn().RemoteMultiaddr())
+
+	messages := make(chan communication.Envelope, 1)
+
+	go func() {
+		communication.HearStream(s, messages)
+		close(messages)
+		m.remove(p)
+	}()
+
+	go m.handle(p, messages)
+	go m.measureLatency(p)
+
+	return p, nil
+}
+
+// measureLatency pings the peer while it is connected, so its latency is
+// kept up to date in the peerstore. A peer that stops answering is
+// disconnected, as its stream may never report the broken link.
+func (m *Manager) measureLatency(p *Peer) {
+
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+
+	failures := 0
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), PingTimeout)
+		result, ok := <-ping.Ping(ctx, m.host, p.ID)
+		cancel()
+
+		if ok && result.Error == nil {
+			failures = 0
+		} else if failures++; failures >= MaxPingFailures {
+			// The whole connection is closed so the peer is redialed on a
+			// new one.
+			logger.Warnf("Peer %s stopped answering pings", p.ID)
+			p.stream.Conn().Close()
+			return
+		}
+
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Latency is the moving average of the round trip time to the peer, or 0
+// when it was not measured yet.
+func (m *Manager) Latency(id peer.ID) time.Duration {
+	return m.host.Peerstore().LatencyEWMA(id)
+}
+
+func (m *Manager) remove(p *Peer) {
+
+	m.mu.Lock()
+	current := m.peers[p.ID] == p
+	if current {
+		delete(m.peers, p.ID)
+	}
+	m.mu.Unlock()
+
+	p.stream.Reset()
+	close(p.done)
+
+	// Requests still waiting for the peer would otherwise only fail once
+	// they time out.
+	p.Pending.Close(http.StatusServiceUnavailable, ErrPeerDisconnected)
+
+	if current {
+		m.record(p.ID, EventDisconnected, nil)
+		logger.Infof("Peer %s disconnected", p.ID)
+	}
+}
+
+func (m *Manager) get(id peer.ID) (*Peer, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.peers[id]
+	return p, ok
+}
+
+// List returns the connected peers sorted by ID.
+func (m *Manager) List() []*Peer {
+
+	m.mu.RLock()
+	list := make([]*Peer, 0, len(m.peers))
+	for _, p := range m.peers {
+		list = append(list, p)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// Select returns the peer with the given alias or ID, or the only connected
+// peer when id is empty.
+func (m *Manager) Select(id string) (*Peer, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if id == "" {
+		switch len(m.peers) {
+		case 0:
+			return nil, ErrNoPeers
+		case 1:
+			for _, p := range m.peers {
+				return p, nil
+			}
+		}
+		return nil, ErrPeerAmbiguous
+	}
+
+	peerID, ok := m.aliases[id]
+	if !ok {
+		var err error
+		peerID, err = peer.Decode(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPeerNotFound, id)
+		}
+	}
+
+	p, ok := m.peers[peerID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPeerNotFound, id)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,72 @@
+package peers
+
+import (
+	"errors"
+	"testing"
+
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/libp2p/go-libp2p/core/peer"
+	p2ptest "github.com/libp2p/go-libp2p/core/test"
+)
+
+// discard is a Handler that ignores every message.
+func discard(p *Peer, messages <-chan communication.Envelope) {
+	for range messages {
+	}
+}
+
+// admin authorizes every peer with full control.
+func admin(peer.ID) (string, error) {
+	return "admin", nil
+}
+
+func TestSelect(t *testing.T) {
+
+	db := p2ptest.RandPeerIDFatal(t)
+	web := p2ptest.RandPeerIDFatal(t)
+	gone := p2ptest.RandPeerIDFatal(t)
+
+	// managerWith returns a manager the given peers are connected to.
+	managerWith := func(ids ...peer.ID) *Manager {
+		m := NewManager(nil, discard, admin)
+		for _, id := range ids {
+			m.peers[id] = &Peer{ID: id}
+		}
+		m.SetAlias("db", db)
+		m.SetAlias("gone", gone)
+		return m
+	}
+
+	tests := []struct {
+		name      string
+		connected []peer.ID
+		selected  string
+		want      peer.ID
+		err       error
+	}{
+		{"no peer", nil, "", "", ErrNoPeers},
+		{"single peer", []peer.ID{db}, "", db, nil},
+		{"several peers", []peer.ID{db, web}, "", "", ErrPeerAmbiguous},
+		{"by alias", []peer.ID{db, web}, "db", db, nil},
+		{"by ID", []peer.ID{db, web}, web.String(), web, nil},
+		{"by ID of the only peer", []peer.ID{web}, web.String(), web, nil},
+		{"alias of a disconnected peer", []peer.ID{db, web}, "gone", "", ErrPeerNotFound},
+		{"ID of a disconnected peer", []peer.ID{db}, gone.String(), "", ErrPeerNotFound},
+		{"unknown alias", []peer.ID{db}, "cache", "", ErrPeerNotFound},
+		{"selected without peers", nil, "db", "", ErrPeerNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			p, err := managerWith(test.connected...).Select(test.selected)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if test.err == nil && p.ID != test.want {
+				t.Fatalf("got peer %s, want %s", p.ID, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package peers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	p2p "github.com/jhonjoao/remote-containers/internal/libp2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/net/swarm"
+)
+
+const (
+	MinReconnectBackoff = time.Second
+	MaxReconnectBackoff = time.Minute
+	DialTimeout         = 30 * time.Second
+	// StableConnection is how long a stream has to last for the backoff
+	// to start over once it ends.
+	StableConnection = 10 * time.Second
+	// MaxEvents is how many connection events are kept for the status
+	// endpoint.
+	MaxEvents = 100
+)
+
+var (
+	ErrPeerDisconnected = errors.New("peer disconnected")
+)
+
+type EventType string
+
+const (
+	EventConnected    EventType = "connected"
+	EventDisconnected EventType = "disconnected"
+	EventDialFailed   EventType = "dial-failed"
+	EventReconnected  EventType = "reconnected"
+)
+
+// Event is a change in the connection to a peer.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Peer  string    `json:"peer"`
+	Type  EventType `json:"type"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Supervision is the state of the connection to a peer the node dialed and
+// keeps reconnecting to.
+type Supervision struct {
+	Peer       string     `json:"peer"`
+	Connected  bool       `json:"connected"`
+	Reconnects int        `json:"reconnects"`
+	Attempts   int        `json:"attempts"`
+	LastError  string     `json:"lastError,omitempty"`
+	NextDial   *time.Time `json:"nextDial,omitempty"`
+}
+
+func (m *Manager) record(id peer.ID, eventType EventType, cause error) {
+
+	event := Event{Time: time.Now(), Peer: id.String(), Type: eventType}
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+
+	m.mu.Lock()
+	m.events = append(m.events, event)
+	if len(m.events) > MaxEvents {
+		m.events = m.events[len(m.events)-MaxEvents:]
+	}
+	m.mu.Unlock()
+}
+
+// Events returns the latest connection events, oldest first.
+func (m *Manager) Events() []Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]Event{}, m.events...)
+}
+
+// Supervised returns the state of the peers the node keeps reconnecting
+// to, sorted by ID.
+func (m *Manager) Supervised() []Supervision {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]Supervision, 0, len(m.supervised))
+	for _, state := range m.supervised {
+		list = append(list, *state)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Peer < list[j].Peer
+	})
+
+	return list
+}
+
+func (m *Manager) updateSupervision(id peer.ID, update func(state *Supervision)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.supervised[id]
+	if !ok {
+		state = &Supervision{Peer: id.String()}
+		m.supervised[id] = state
+	}
+
+	update(state)
+}
+
+// Connect keeps the node connected to the peer until ctx is done. Whenever
+// its stream ends the peer is redialed, at the addresses in the peerstore,
+// with an exponential backoff between failed attempts.
+func (m *Manager) Connect(ctx context.Context, id peer.ID) {
+
+	m.updateSupervision(id, func(state *Supervision) {})
+
+	backoff := MinReconnectBackoff
+	connected := false
+
+	for ctx.Err() == nil {
+
+		// The peer may have dialed this node in the meantime, in which case
+		// its stream is used until it ends too.
+		current, ok := m.get(id)
+		if !ok {
+			// libp2p has a dial backoff of its own, which would fail the
+			// attempt right away.
+			if sw, ok := m.host.Network().(*swarm.Swarm); ok {
+				sw.Backoff().Clear(id)
+			}
+
+			dialCtx, cancel := context.WithTimeout(ctx, DialTimeout)
+			s, err := m.host.NewStream(dialCtx, id, communication.MessageProtocol)
+			cancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				err = p2p.DialError(err)
+
+				next := time.Now().Add(backoff)
+				m.updateSupervision(id, func(state *Supervision) {
+					state.Attempts++
+					state.LastError = err.Error()
+					state.NextDial = &next
+				})
+				m.record(id, EventDialFailed, err)
+				logger.Warnf("Failed to connect to peer %s, retrying in %s: %s", id, backoff, err)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+
+				backoff = min(backoff*2, MaxReconnectBackoff)
+				continue
+			}
+
+			current, err = m.Add(s)
+			if err != nil {
+				m.updateSupervision(id, func(state *Supervision) {
+					state.LastError = err.Error()
+				})
+				m.record(id, EventDialFailed, err)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+
+				backoff = min(backoff*2, MaxReconnectBackoff)
+				continue
+			}
+
+			if connected {
+				m.record(id, EventReconnected, nil)
+				logger.Infof("Reconnected to peer %s", id)
+			}
+		}
+
+		m.updateSupervision(id, func(state *Supervision) {
+			if connected && !ok {
+				state.Reconnects++
+			}
+			state.Connected = true
+			state.Attempts = 0
+			state.LastError = ""
+			state.NextDial = nil
+		})
+
+		connected = true
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-current.done:
+		}
+
+		m.updateSupervision(id, func(state *Supervision) {
+			state.Connected = false
+		})
+
+		if time.Since(current.ConnectedAt) >= StableConnection {
+			backoff = MinReconnectBackoff
+			continue
+		}
+
+		// A peer that drops the stream right away, as one that doesn't
+		// authorize this node does, is redialed with a backoff too.
+		next := time.Now().Add(backoff)
+		m.updateSupervision(id, func(state *Supervision) {
+			state.NextDial = &next
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, MaxReconnectBackoff)
+	}
+}
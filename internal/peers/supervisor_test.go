@@ -0,0 +1,98 @@
+package peers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+// waitFor polls condition until it holds, failing the test after a few
+// reconnect backoffs.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * MinReconnectBackoff)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConnectReconnects(t *testing.T) {
+
+	network := mocknet.New()
+	t.Cleanup(func() { network.Close() })
+
+	local, err := network.GenPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := network.GenPeer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.LinkAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	remoteManager := NewManager(remote, discard, admin)
+	remote.SetStreamHandler(communication.MessageProtocol, remoteManager.HandleStream)
+
+	manager := NewManager(local, discard, admin)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go manager.Connect(ctx, remote.ID())
+
+	var first *Peer
+	waitFor(t, "the peer is connected", func() bool {
+		first, _ = manager.get(remote.ID())
+		return first != nil
+	})
+
+	first.Pending.Register("in-flight")
+
+	// The other node drops the stream, as it would when it restarts.
+	waitFor(t, "the other node sees the peer", func() bool {
+		_, ok := remoteManager.get(local.ID())
+		return ok
+	})
+	dropped, _ := remoteManager.get(local.ID())
+	dropped.stream.Reset()
+
+	response, err := first.Pending.Wait(context.Background(), "in-flight", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Kind != communication.KindError || response.Status != http.StatusServiceUnavailable {
+		t.Fatalf("in-flight request got %s with status %d, want an error with status %d", response.Kind, response.Status, http.StatusServiceUnavailable)
+	}
+
+	waitFor(t, "the peer is reconnected", func() bool {
+		current, ok := manager.get(remote.ID())
+		return ok && current != first
+	})
+
+	waitFor(t, "the reconnection is recorded", func() bool {
+		supervised := manager.Supervised()
+		return len(supervised) == 1 && supervised[0].Connected && supervised[0].Reconnects == 1
+	})
+
+	var types []EventType
+	for _, event := range manager.Events() {
+		types = append(types, event.Type)
+	}
+
+	want := []EventType{EventConnected, EventDisconnected, EventConnected, EventReconnected}
+	if !slices.Equal(types, want) {
+		t.Fatalf("got events %v, want %v", types, want)
+	}
+}
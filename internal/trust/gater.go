@@ -0,0 +1,54 @@
+package trust
+
+import (
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// Gater is the libp2p connection gater that closes every connection, in
+// either direction, to a peer missing from the store. It never pins peers
+// trusted on first use, that is left to the stream handlers once the peer
+// completed a handshake.
+type Gater struct {
+	Store *Store
+	// Allow lets some peers missing from the store connect anyway, such as
+	// those being paired. ResourceManager limits the streams they can open.
+	Allow func(id peer.ID) bool
+}
+
+func (g Gater) InterceptPeerDial(id peer.ID) bool {
+	return g.allowed(id, "dial")
+}
+
+func (g Gater) InterceptAddrDial(peer.ID, multiaddr.Multiaddr) bool {
+	return true
+}
+
+func (g Gater) InterceptAccept(network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured is where the ID of a peer dialing this node is first
+// known, once the security handshake proved it.
+func (g Gater) InterceptSecured(direction network.Direction, id peer.ID, addrs network.ConnMultiaddrs) bool {
+	if direction == network.DirOutbound {
+		return true
+	}
+
+	return g.allowed(id, "connection from "+addrs.RemoteMultiaddr().String())
+}
+
+func (g Gater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
+
+func (g Gater) allowed(id peer.ID, attempt string) bool {
+	if g.Store.Allowed(id) || (g.Allow != nil && g.Allow(id)) {
+		return true
+	}
+
+	logger.Warnf("Rejected %s of peer %s: %s", attempt, id, ErrUnauthorized)
+	return false
+}
@@ -0,0 +1,46 @@
+package trust
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// ResourceManager wraps the libp2p resource manager so the peers missing
+// from the store, which the Allow function of the Gater lets connect, can
+// only open streams of the given protocols. Streams of other protocols are
+// reset once negotiated, before they reach their handler.
+type ResourceManager struct {
+	network.ResourceManager
+	Store     *Store
+	Protocols []protocol.ID
+}
+
+func (r ResourceManager) OpenStream(id peer.ID, direction network.Direction) (network.StreamManagementScope, error) {
+
+	scope, err := r.ResourceManager.OpenStream(id, direction)
+	if err != nil || direction != network.DirInbound || r.Store.Allowed(id) {
+		return scope, err
+	}
+
+	return restrictedStream{StreamManagementScope: scope, peer: id, protocols: r.Protocols}, nil
+}
+
+type restrictedStream struct {
+	network.StreamManagementScope
+	peer      peer.ID
+	protocols []protocol.ID
+}
+
+func (s restrictedStream) SetProtocol(proto protocol.ID) error {
+
+	if !slices.Contains(s.protocols, proto) {
+		logger.Warnf("Rejected %s stream of peer %s: %s", proto, s.peer, ErrUnauthorized)
+		return fmt.Errorf("%w: %s may not open %s streams", ErrUnauthorized, s.peer, proto)
+	}
+
+	return s.StreamManagementScope.SetProtocol(proto)
+}
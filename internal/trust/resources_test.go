@@ -0,0 +1,67 @@
+package trust
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+func TestResourceManagerRestrictsUnknownPeers(t *testing.T) {
+
+	store, err := Load(filepath.Join(t.TempDir(), "authorized_peers.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known, unknown := newPeerID(t), newPeerID(t)
+	if err := store.Add(Entry{ID: known.String()}); err != nil {
+		t.Fatal(err)
+	}
+
+	const pairing = protocol.ID("/pair/1.0.0")
+	const other = protocol.ID("/other/1.0.0")
+
+	resources := ResourceManager{
+		ResourceManager: &network.NullResourceManager{},
+		Store:           store,
+		Protocols:       []protocol.ID{pairing},
+	}
+
+	setProtocol := func(t *testing.T, direction network.Direction, proto protocol.ID) error {
+		t.Helper()
+
+		scope, err := resources.OpenStream(unknown, direction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return scope.SetProtocol(proto)
+	}
+
+	if err := setProtocol(t, network.DirInbound, pairing); err != nil {
+		t.Fatalf("pairing stream of an unknown peer rejected: %v", err)
+	}
+	if err := setProtocol(t, network.DirInbound, other); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("got %v for another stream of an unknown peer, want %v", err, ErrUnauthorized)
+	}
+	if err := setProtocol(t, network.DirOutbound, other); err != nil {
+		t.Fatalf("outbound stream rejected: %v", err)
+	}
+
+	scope, err := resources.OpenStream(known, network.DirInbound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scope.SetProtocol(other); err != nil {
+		t.Fatalf("stream of a known peer rejected: %v", err)
+	}
+
+	// Peers trusted on first use are only pinned once connected, their
+	// streams are not restricted.
+	store.TrustOnFirstUse = true
+	if err := setProtocol(t, network.DirInbound, other); err != nil {
+		t.Fatalf("stream of a peer trusted on first use rejected: %v", err)
+	}
+}
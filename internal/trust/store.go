@@ -0,0 +1,241 @@
+package trust
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"gopkg.in/yaml.v3"
+)
+
+var logger = logging.Logger("remote-containers/trust")
+
+const (
+	// RoleAdmin has full control of the Docker daemon of the node.
+	RoleAdmin = "admin"
+	// RoleViewer can only send GET requests and can't open exec sessions.
+	RoleViewer = "viewer"
+)
+
+var ErrUnauthorized = errors.New("peer is not authorized")
+
+// Entry is an authorized peer. Name is accepted by the HTTP API in place of
+// the peer ID, and Role defaults to admin.
+type Entry struct {
+	ID   string `yaml:"id" json:"id"`
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	Role string `yaml:"role,omitempty" json:"role,omitempty"`
+}
+
+func (entry Entry) Validate() error {
+
+	if _, err := peer.Decode(entry.ID); err != nil {
+		return fmt.Errorf("invalid peer ID %q: %w", entry.ID, err)
+	}
+
+	if err := ValidateRole(entry.Role); err != nil {
+		return fmt.Errorf("%w for peer %s", err, entry.ID)
+	}
+
+	return nil
+}
+
+// ValidateRole accepts the known roles and the empty one, which stands for
+// admin.
+func ValidateRole(role string) error {
+	switch role {
+	case "", RoleAdmin, RoleViewer:
+		return nil
+	default:
+		return fmt.Errorf("invalid role %q, expected %s or %s", role, RoleAdmin, RoleViewer)
+	}
+}
+
+type file struct {
+	Peers []Entry `yaml:"peers"`
+}
+
+// Store is the list of peers allowed to connect to this node, kept in a
+// YAML file:
+//
+//	peers:
+//	  - id: 12D3KooW...
+//	    name: build-server
+//	    role: viewer
+type Store struct {
+	path string
+	// TrustOnFirstUse accepts unknown peers and adds them to the store as
+	// viewers, to pair nodes without copying peer IDs around.
+	TrustOnFirstUse bool
+
+	mu      sync.RWMutex
+	entries map[peer.ID]Entry
+}
+
+// DefaultPath is remote-containers/authorized_peers.yaml in the user config
+// dir.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "remote-containers", "authorized_peers.yaml"), nil
+}
+
+// Load reads the store from path. A missing file is an empty store, which
+// is created once a peer is added.
+func Load(path string) (*Store, error) {
+
+	store := &Store{path: path, entries: map[peer.ID]Entry{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorized peers: %w", err)
+	}
+
+	var content file
+	err = yaml.Unmarshal(data, &content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorized peers file %s: %w", path, err)
+	}
+
+	for _, entry := range content.Peers {
+		if err := entry.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid authorized peers file %s: %w", path, err)
+		}
+
+		id, _ := peer.Decode(entry.ID)
+		store.entries[id] = entry
+	}
+
+	return store, nil
+}
+
+// Lookup returns the entry of the peer, with its role filled in.
+func (s *Store) Lookup(id peer.ID) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[id]
+	if ok && entry.Role == "" {
+		entry.Role = RoleAdmin
+	}
+
+	return entry, ok
+}
+
+// List returns every authorized peer.
+func (s *Store) List() []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		list = append(list, entry)
+	}
+
+	return list
+}
+
+// Allowed reports whether the peer may connect: it is known, or the store
+// trusts on first use. Unlike Authorize it never pins the peer.
+func (s *Store) Allowed(id peer.ID) bool {
+	_, ok := s.Lookup(id)
+	return ok || s.TrustOnFirstUse
+}
+
+// Authorize returns the entry of the peer, pinning it first as a viewer
+// when it is unknown and the store trusts on first use.
+func (s *Store) Authorize(id peer.ID) (Entry, error) {
+
+	if entry, ok := s.Lookup(id); ok {
+		return entry, nil
+	}
+
+	if !s.TrustOnFirstUse {
+		return Entry{}, fmt.Errorf("%w: %s", ErrUnauthorized, id)
+	}
+
+	err := s.Add(Entry{ID: id.String(), Role: RoleViewer})
+	if err != nil {
+		return Entry{}, err
+	}
+
+	logger.Infof("Pinned peer %s on first use", id)
+
+	entry, _ := s.Lookup(id)
+	return entry, nil
+}
+
+// Add authorizes the peer, replacing a previous entry with the same ID,
+// and saves the store.
+func (s *Store) Add(entry Entry) error {
+
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	id, _ := peer.Decode(entry.ID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[id] = entry
+
+	return s.save()
+}
+
+func (s *Store) save() error {
+
+	content := file{Peers: make([]Entry, 0, len(s.entries))}
+	for _, entry := range s.entries {
+		content.Peers = append(content.Peers, entry)
+	}
+	sort.Slice(content.Peers, func(i, j int) bool {
+		return content.Peers[i].ID < content.Peers[j].ID
+	})
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to save authorized peers: %w", err)
+	}
+
+	// Written to a temporary file first so a crash never leaves a
+	// truncated list behind.
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".authorized_peers-*")
+	if err != nil {
+		return fmt.Errorf("failed to save authorized peers: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save authorized peers: %w", err)
+	}
+
+	return nil
+}
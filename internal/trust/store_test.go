@@ -0,0 +1,147 @@
+package trust
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func newPeerID(t *testing.T) peer.ID {
+	t.Helper()
+
+	key, _, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return id
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config", "authorized_peers.yaml")
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, viewer, unset := newPeerID(t), newPeerID(t), newPeerID(t)
+
+	entries := []Entry{
+		{ID: admin.String(), Name: "build", Role: RoleAdmin},
+		{ID: viewer.String(), Name: "laptop", Role: RoleViewer},
+		{ID: unset.String()},
+	}
+	for _, entry := range entries {
+		if err := store.Add(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("got file mode %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded.List()) != len(entries) {
+		t.Fatalf("got %d entries, want %d", len(loaded.List()), len(entries))
+	}
+
+	tests := map[peer.ID]Entry{
+		admin:  {ID: admin.String(), Name: "build", Role: RoleAdmin},
+		viewer: {ID: viewer.String(), Name: "laptop", Role: RoleViewer},
+		// Entries without a role are admins.
+		unset: {ID: unset.String(), Role: RoleAdmin},
+	}
+	for id, want := range tests {
+		got, ok := loaded.Lookup(id)
+		if !ok || got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+
+	if _, ok := loaded.Lookup(newPeerID(t)); ok {
+		t.Fatal("unknown peer found in the store")
+	}
+}
+
+func TestLoadRejectsInvalidEntries(t *testing.T) {
+
+	files := map[string]string{
+		"peer ID": "peers:\n  - id: not-a-peer\n",
+		"role":    "peers:\n  - id: " + newPeerID(t).String() + "\n    role: owner\n",
+		"yaml":    "peers: [",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "authorized_peers.yaml")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Load(path); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+
+	store, err := Load(filepath.Join(t.TempDir(), "authorized_peers.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := newPeerID(t)
+
+	if store.Allowed(unknown) {
+		t.Fatal("unknown peer allowed")
+	}
+	if _, err := store.Authorize(unknown); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("got %v, want %v", err, ErrUnauthorized)
+	}
+
+	store.TrustOnFirstUse = true
+
+	if !store.Allowed(unknown) {
+		t.Fatal("unknown peer not allowed on first use")
+	}
+	if _, ok := store.Lookup(unknown); ok {
+		t.Fatal("peer pinned by Allowed")
+	}
+
+	entry, err := store.Authorize(unknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Role != RoleViewer {
+		t.Fatalf("peer pinned as %q, want %q", entry.Role, RoleViewer)
+	}
+
+	// Once pinned the peer stays authorized with trust on first use off.
+	store.TrustOnFirstUse = false
+
+	if entry, err := store.Authorize(unknown); err != nil || entry.Role != RoleViewer {
+		t.Fatalf("got %+v and %v after pinning", entry, err)
+	}
+}
@@ -1,103 +1,464 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
-	"strings"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	logging "github.com/ipfs/go-log/v2"
 	api "github.com/jhonjoao/remote-containers/cmd/api"
 	internalApi "github.com/jhonjoao/remote-containers/cmd/internalApi"
 	communication "github.com/jhonjoao/remote-containers/internal/communication"
+	"github.com/jhonjoao/remote-containers/internal/config"
 	"github.com/jhonjoao/remote-containers/internal/docker"
 	p2p "github.com/jhonjoao/remote-containers/internal/libp2p"
-	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/jhonjoao/remote-containers/internal/pairing"
+	"github.com/jhonjoao/remote-containers/internal/peers"
+	"github.com/jhonjoao/remote-containers/internal/trust"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/urfave/cli/v2"
 )
 
-var stream network.Stream
-var responseChan chan communication.ResponseData
-var apiChan chan communication.ResponseData
+var logger = logging.Logger("remote-containers")
+
+var manager *peers.Manager
+var settings config.Config
+var apiListener net.Listener
 
 func main() {
 
+	app := &cli.App{
+		Name:  "remote-containers",
+		Usage: "manage Docker containers on remote machines over libp2p",
+		Flags: config.Flags(),
+		Before: func(c *cli.Context) error {
+			var err error
+			settings, err = config.Load(c)
+			if err != nil {
+				return err
+			}
+
+			setLogLevel(settings.LogLevel)
+			return nil
+		},
+		Action: run,
+		Commands: []*cli.Command{
+			{
+				Name:  "id",
+				Usage: "print the peer ID and multiaddrs of this node and exit",
+				Action: func(c *cli.Context) error {
+					identity, err := loadIdentity()
+					if err != nil {
+						return err
+					}
+
+					return printIdentity(identity)
+				},
+			},
+			{
+				Name:      "keygen",
+				Usage:     "generate the pre-shared key of a private network",
+				ArgsUsage: "[file]",
+				Description: "The key is written to file, or printed when no file is given. Copy it to every\n" +
+					"node of the private network and start them with --swarm-key file.",
+				Action: func(c *cli.Context) error {
+					return generateSwarmKey(c.Args().First())
+				},
+			},
+			pairCommand,
+		},
+	}
+
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func run(c *cli.Context) error {
+
+	identity, err := loadIdentity()
+	if err != nil {
+		return err
+	}
+
+	// Both ports are bound before anything else so a port in use stops the
+	// node right away.
+	apiListener, err = api.Listen(settings.Api)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	h, err := p2p.NewHost(ctx)
+	authorized, err := loadAuthorizedPeers()
+	if err != nil {
+		return err
+	}
+
+	var psk pnet.PSK
+	if settings.SwarmKey != "" {
+		psk, err = p2p.LoadSwarmKey(settings.SwarmKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Unknown peers may connect while a pairing token is open, but only to
+	// pair.
+	pairings := pairing.New(authorized)
+	gater := trust.Gater{Store: authorized, Allow: pairings.Allow}
 
+	limits := rcmgr.DefaultLimits
+	libp2p.SetDefaultServiceLimits(&limits)
+	resources, err := rcmgr.NewResourceManager(rcmgr.NewFixedLimiter(limits.AutoScale()))
 	if err != nil {
-		log.Fatalln(err)
-		os.Exit(1)
+		return err
+	}
+	restricted := trust.ResourceManager{
+		ResourceManager: resources,
+		Store:           authorized,
+		Protocols:       []protocol.ID{communication.PairingProtocol},
 	}
 
-	dest := Input("Do you like to connect to another machine? (No - empty)")
+	h, err := p2p.NewHost(ctx, identity, settings.Listen, psk, libp2p.ConnectionGater(gater), libp2p.ResourceManager(restricted))
+	if err != nil {
+		return err
+	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
 	go func() {
-		<-c
-		fmt.Println("\nExiting....")
+		<-signals
+		logger.Info("Exiting")
 		h.Close()
 		// <-ctx.Done()
 		// code to kill connection
 		os.Exit(1)
 	}()
 
-	responseChan = make(chan communication.ResponseData, 1)
-	apiChan = make(chan communication.ResponseData, 1)
+	// The gater already closed the connections of unknown peers. Streams
+	// are authorized again for the role of the peer, and to pin the peers
+	// trusted on first use. The file is only read at startup.
+	manager = peers.NewManager(h, internalApi.MessageHandler(docker.New()), func(id peer.ID) (string, error) {
+		entry, err := authorized.Authorize(id)
+		return entry.Role, err
+	})
 
-	if dest == "" {
-		p2p.StartPeer(ctx, h, handleStream)
+	// The names in the authorized peers file are aliases too, unless an
+	// alias of the configuration takes them.
+	for _, entry := range authorized.List() {
+		if _, taken := settings.Aliases[entry.Name]; entry.Name == "" || taken {
+			continue
+		}
+		id, _ := peer.Decode(entry.ID)
+		manager.SetAlias(entry.Name, id)
+	}
+	for name, id := range settings.Aliases {
+		manager.SetAlias(name, id)
+	}
 
-		select {}
+	h.SetStreamHandler(communication.ExecProtocol, manager.Authorized(internalApi.ExecStreamHandler(docker.New())))
+	h.SetStreamHandler(communication.ArchiveProtocol, manager.Authorized(internalApi.ArchiveStreamHandler(docker.New())))
 
-	} else {
-		s, err := p2p.StartPeerAndConnect(ctx, h, dest)
+	p2p.StartPeer(ctx, h, manager.HandleStream)
+
+	// The node that joined another one keeps reconnecting to it, like
+	// those given with --peer.
+	pairings.Paired = func(entry trust.Entry, addrs []multiaddr.Multiaddr) {
+		id, _ := peer.Decode(entry.ID)
+		if _, taken := settings.Aliases[entry.Name]; entry.Name != "" && !taken {
+			manager.SetAlias(entry.Name, id)
+		}
+
+		if len(addrs) > 0 {
+			h.Peerstore().AddAddrs(id, addrs, peerstore.PermanentAddrTTL)
+			go manager.Connect(ctx, id)
+		}
+	}
+	pairings.Start(h)
+
+	// The peers given in the configuration are redialed whenever their
+	// connection drops.
+	for _, dest := range settings.Peers {
+		id, err := p2p.AddPeerAddress(h, dest)
+		if err != nil {
+			return err
+		}
+
+		go manager.Connect(ctx, id)
+	}
+
+	if settings.Mdns {
+		stopDiscovery, err := p2p.StartDiscovery(h, settings.Cluster, func(info peer.AddrInfo, inCluster bool) {
+			if !manager.Discover(info, inCluster) {
+				return
+			}
+
+			if inCluster {
+				logger.Infof("Found peer %s of cluster %s on the local network", info.ID, settings.Cluster)
+			} else {
+				logger.Infof("Found peer %s on the local network", info.ID)
+			}
+
+			// Only one side of a pair dials, or each would replace the
+			// stream opened by the other.
+			if settings.AutoConnect && inCluster && h.ID() < info.ID {
+				h.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.PermanentAddrTTL)
+				go manager.Connect(ctx, info.ID)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		defer stopDiscovery()
+	}
+
+	return api.StartApi(manager, pairings, apiListener)
+}
+
+func loadAuthorizedPeers() (*trust.Store, error) {
+
+	path := settings.AuthorizedPeers
+	if path == "" {
+		var err error
+		path, err = trust.DefaultPath()
+		if err != nil {
+			return nil, fmt.Errorf("no authorized peers file given and no config dir to keep one in: %w", err)
+		}
+	}
+
+	store, err := trust.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	store.TrustOnFirstUse = settings.TrustOnFirstUse
+
+	if len(store.List()) == 0 && !store.TrustOnFirstUse {
+		logger.Warnf("No peer is authorized in %s, every connection will be rejected", path)
+	}
+
+	return store, nil
+}
+
+// generateSwarmKey writes a new pre-shared key to path, without replacing
+// an existing one, or prints it when path is empty.
+func generateSwarmKey(path string) error {
+
+	key, err := p2p.SwarmKey()
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		_, err = os.Stdout.Write(key)
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create swarm key: %w", err)
+	}
+
+	_, err = file.Write(key)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save swarm key: %w", err)
+	}
+
+	psk, err := p2p.LoadSwarmKey(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Swarm key %s saved to %s\n", p2p.Fingerprint(psk), path)
+	return nil
+}
+
+func loadIdentity() (crypto.PrivKey, error) {
+
+	path := settings.Identity
+	if path == "" {
+		var err error
+		path, err = p2p.DefaultIdentityPath()
 		if err != nil {
-			log.Fatalln(err)
-			os.Exit(1)
+			return nil, fmt.Errorf("no identity file given and no config dir to keep one in: %w", err)
 		}
+	}
 
-		stream = *s
+	return p2p.LoadIdentity(path)
+}
 
-		dockerClient := docker.New()
+// printIdentity shows how other machines can reach this node. When the
+// listen port is 0 it is only picked when the node starts, so it is left as
+// a placeholder.
+func printIdentity(identity crypto.PrivKey) error {
 
-		go communication.HearStream(*s, responseChan)
-		go internalApi.ProcessInternalData(*s, dockerClient, responseChan, apiChan)
+	id, err := peer.IDFromPrivateKey(identity)
+	if err != nil {
+		return err
+	}
+
+	address, port, err := net.SplitHostPort(settings.Listen)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", settings.Listen, err)
+	}
+
+	ips := p2p.LocalIPs()
+	if ip := net.ParseIP(address); ip != nil && !ip.IsUnspecified() {
+		ips = []net.IP{ip}
+	}
+
+	fmt.Println("Peer ID:", id)
+	if port == "0" {
+		port = "PORT"
+		fmt.Println("Multiaddrs (replace PORT with the port shown when the node starts):")
+	} else {
+		fmt.Println("Multiaddrs:")
+	}
 
-		api.StartApi(stream, apiChan)
+	for _, ip := range ips {
+		protocol := "ip4"
+		if ip.To4() == nil {
+			protocol = "ip6"
+		}
+		fmt.Printf(" - /%s/%s/tcp/%s/p2p/%s\n", protocol, ip, port, id)
 	}
 
+	return nil
 }
-func handleStream(s network.Stream) {
 
-	log.Println("Got a new stream!")
+// setLogLevel applies the configured level to the loggers of the node and
+// of libp2p, and to gin, which only logs every request in debug.
+func setLogLevel(level string) {
 
-	stream = s
+	if level == "debug" {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	lvl, err := logging.LevelFromString(level)
+	if err == nil {
+		logging.SetAllLoggers(lvl)
+	}
+}
 
-	dockerClient := docker.New()
+// pairCommand pairs two nodes through the HTTP API of the node running on
+// this machine, found at the --api address.
+var pairCommand = &cli.Command{
+	Name:  "pair",
+	Usage: "pair the running node with another one",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "token",
+			Usage: "print a one-time token for another node to join this one",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "name", Usage: "`name` the joining node is given here"},
+				&cli.StringFlag{Name: "role", Usage: "role of the joining node, admin or viewer", Value: trust.RoleAdmin},
+				&cli.DurationFlag{Name: "ttl", Usage: "how long the token can be used", Value: pairing.DefaultTTL},
+			},
+			Action: func(c *cli.Context) error {
+				var token pairing.Token
+				err := callApi(http.MethodPost, "/pairing/tokens", api.IssueTokenRequest{
+					Name: c.String("name"),
+					Role: c.String("role"),
+					Ttl:  c.Duration("ttl").String(),
+				}, &token)
+				if err != nil {
+					return err
+				}
 
-	go communication.HearStream(s, responseChan)
-	go internalApi.ProcessInternalData(s, dockerClient, responseChan, apiChan)
+				fmt.Println(token.Token)
+				fmt.Printf("\nValid once until %s. On the other machine run:\n\n", token.ExpiresAt.Local().Format(time.Kitchen))
+				fmt.Printf("  remote-containers pair join %s\n", token.Token)
+				return nil
+			},
+		},
+		{
+			Name:      "join",
+			Usage:     "join the node that printed a pairing token",
+			ArgsUsage: "token",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "name", Usage: "`name` the other node is given here"},
+				&cli.StringFlag{Name: "role", Usage: "role of the other node here, admin or viewer", Value: trust.RoleViewer},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return fmt.Errorf("expected a pairing token")
+				}
 
-	api.StartApi(stream, apiChan)
+				var entry trust.Entry
+				err := callApi(http.MethodPost, "/pairing/join", api.JoinRequest{
+					Token: c.Args().First(),
+					Name:  c.String("name"),
+					Role:  c.String("role"),
+				}, &entry)
+				if err != nil {
+					return err
+				}
 
+				fmt.Println("Paired with peer", entry.ID)
+				return nil
+			},
+		},
+	},
 }
 
-func Input(label string) string {
-	var s string
-	r := bufio.NewReader(os.Stdin)
+// callApi sends body to the HTTP API of the local node and decodes its
+// answer into out.
+func callApi(method, path string, body, out interface{}) error {
+
+	host, port, err := net.SplitHostPort(settings.Api)
+	if err != nil {
+		return fmt.Errorf("invalid API address %q: %w", settings.Api, err)
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(method, "http://"+net.JoinHostPort(host, port)+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to reach the node, is it running with --api %s? %w", settings.Api, err)
+	}
+	defer response.Body.Close()
 
-	for {
-		fmt.Fprint(os.Stderr, label+" ")
-		s, _ = r.ReadString('\n')
-		if s != "" {
-			break
+	if response.StatusCode >= http.StatusBadRequest {
+		var failure struct {
+			Error string `json:"error"`
 		}
+		json.NewDecoder(response.Body).Decode(&failure)
+		return fmt.Errorf("%s: %s", response.Status, failure.Error)
 	}
 
-	return strings.TrimSpace(s)
+	return json.NewDecoder(response.Body).Decode(out)
 }